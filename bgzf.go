@@ -0,0 +1,205 @@
+package gfa
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+)
+
+// bgzfBlockSize is the maximum number of uncompressed bytes held in a BGZF block (the size used by htslib)
+const bgzfBlockSize = 0xff00
+
+// bgzfEOF is the empty block that marks the end of a BGZF file
+var bgzfEOF = []byte{
+	0x1f, 0x8b, 0x08, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x06, 0x00, 0x42, 0x43, 0x02, 0x00,
+	0x1b, 0x00, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+}
+
+// A bgzfWriter compresses data into the blocked gzip format (BGZF) used by htslib, where each block is a gzip member
+// that records its own compressed size, so that a file can be indexed and accessed at random
+type bgzfWriter struct {
+	w      io.Writer
+	buf    []byte // uncompressed data waiting to be written as a block
+	offset int64  // the number of compressed bytes written so far
+	err    error
+}
+
+// newBGZFWriter returns a bgzfWriter that writes to the given io.Writer
+func newBGZFWriter(w io.Writer) *bgzfWriter {
+	return &bgzfWriter{w: w, buf: make([]byte, 0, bgzfBlockSize)}
+}
+
+// Write adds data to the BGZF stream, writing blocks as they are filled
+func (bw *bgzfWriter) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) != 0 && bw.err == nil {
+		free := bgzfBlockSize - len(bw.buf)
+		if free > len(p) {
+			free = len(p)
+		}
+		bw.buf = append(bw.buf, p[:free]...)
+		p = p[free:]
+		n += free
+		if len(bw.buf) == bgzfBlockSize {
+			bw.err = bw.writeBlock()
+		}
+	}
+	return n, bw.err
+}
+
+// VirtualOffset returns the BGZF virtual offset that the next byte written will have
+func (bw *bgzfWriter) VirtualOffset() int64 {
+	return bw.offset<<16 | int64(len(bw.buf))
+}
+
+// Flush writes any buffered data as a block
+func (bw *bgzfWriter) Flush() error {
+	if bw.err == nil && len(bw.buf) != 0 {
+		bw.err = bw.writeBlock()
+	}
+	return bw.err
+}
+
+// Close flushes any buffered data and writes the BGZF end of file block, the underlying io.Writer is not closed
+func (bw *bgzfWriter) Close() error {
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	_, bw.err = bw.w.Write(bgzfEOF)
+	return bw.err
+}
+
+// writeBlock compresses the buffered data and writes it as a single BGZF block
+func (bw *bgzfWriter) writeBlock() error {
+	var compressed bytes.Buffer
+	fw, err := flate.NewWriter(&compressed, flate.DefaultCompression)
+	if err != nil {
+		return err
+	}
+	if _, err := fw.Write(bw.buf); err != nil {
+		return err
+	}
+	if err := fw.Close(); err != nil {
+		return err
+	}
+	// the gzip header, with the BC extra subfield holding the total block size minus 1
+	header := []byte{0x1f, 0x8b, 0x08, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x06, 0x00, 0x42, 0x43, 0x02, 0x00, 0x00, 0x00}
+	blockSize := len(header) + compressed.Len() + 8
+	binary.LittleEndian.PutUint16(header[16:], uint16(blockSize-1))
+	footer := make([]byte, 8)
+	binary.LittleEndian.PutUint32(footer, crc32.ChecksumIEEE(bw.buf))
+	binary.LittleEndian.PutUint32(footer[4:], uint32(len(bw.buf)))
+	for _, b := range [][]byte{header, compressed.Bytes(), footer} {
+		if _, err := bw.w.Write(b); err != nil {
+			return err
+		}
+	}
+	bw.offset += int64(blockSize)
+	bw.buf = bw.buf[:0]
+	return nil
+}
+
+// A bgzfReader decompresses a BGZF stream block by block, keeping track of the virtual offset of the data it returns
+type bgzfReader struct {
+	r           *bufio.Reader
+	offset      int64  // the file offset of the next block
+	blockOffset int64  // the file offset of the current block
+	block       []byte // the decompressed data of the current block
+	pos         int    // the position in the current block
+}
+
+// newBGZFReader returns a bgzfReader that reads from the given io.Reader, which must be at the start of a block
+func newBGZFReader(r io.Reader) *bgzfReader {
+	return &bgzfReader{r: bufio.NewReader(r)}
+}
+
+// VirtualOffset returns the BGZF virtual offset of the next byte to be read
+func (br *bgzfReader) VirtualOffset() int64 {
+	if br.pos == len(br.block) {
+		return br.offset << 16
+	}
+	return br.blockOffset<<16 | int64(br.pos)
+}
+
+// readBlock reads and decompresses the next BGZF block
+func (br *bgzfReader) readBlock() error {
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(br.r, header); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return fmt.Errorf("truncated BGZF block")
+		}
+		return err
+	}
+	if header[0] != 0x1f || header[1] != 0x8b || header[3]&0x04 == 0 {
+		return fmt.Errorf("not a BGZF block")
+	}
+	// find the block size in the BC extra subfield
+	extra := make([]byte, binary.LittleEndian.Uint16(header[10:]))
+	if _, err := io.ReadFull(br.r, extra); err != nil {
+		return fmt.Errorf("truncated BGZF block")
+	}
+	blockSize := -1
+	for len(extra) >= 4 {
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		if extra[0] == 'B' && extra[1] == 'C' && size == 2 && len(extra) >= 6 {
+			blockSize = int(binary.LittleEndian.Uint16(extra[4:])) + 1
+		}
+		if len(extra) < 4+size {
+			break
+		}
+		extra = extra[4+size:]
+	}
+	if blockSize < 0 {
+		return fmt.Errorf("BGZF block has no BC field")
+	}
+	headerSize := 12 + int(binary.LittleEndian.Uint16(header[10:]))
+	if blockSize < headerSize+8 {
+		return fmt.Errorf("BGZF block size is too small: %d", blockSize)
+	}
+	data := make([]byte, blockSize-headerSize)
+	if _, err := io.ReadFull(br.r, data); err != nil {
+		return fmt.Errorf("truncated BGZF block")
+	}
+	block, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(data[:len(data)-8])))
+	if err != nil {
+		return err
+	}
+	if crc32.ChecksumIEEE(block) != binary.LittleEndian.Uint32(data[len(data)-8:]) {
+		return fmt.Errorf("BGZF block failed checksum")
+	}
+	br.blockOffset, br.offset = br.offset, br.offset+int64(blockSize)
+	br.block, br.pos = block, 0
+	return nil
+}
+
+// ReadLine returns the next line (including its line ending) and the virtual offset of its start
+func (br *bgzfReader) ReadLine() ([]byte, int64, error) {
+	var line []byte
+	start := int64(-1)
+	for {
+		for br.pos == len(br.block) {
+			if err := br.readBlock(); err != nil {
+				if err == io.EOF && len(line) != 0 {
+					return line, start, nil
+				}
+				return nil, 0, err
+			}
+		}
+		if start < 0 {
+			start = br.VirtualOffset()
+		}
+		end := bytes.IndexByte(br.block[br.pos:], '\n')
+		if end >= 0 {
+			line = append(line, br.block[br.pos:br.pos+end+1]...)
+			br.pos += end + 1
+			return line, start, nil
+		}
+		line = append(line, br.block[br.pos:]...)
+		br.pos = len(br.block)
+	}
+}
@@ -0,0 +1,268 @@
+package gfa
+
+import (
+	"sort"
+	"strings"
+)
+
+// A Bubble is a superbubble: a part of the graph that is entered only through its source and left only through its sink, with no
+// cycles and no tips inside it
+type Bubble struct {
+	Source     Handle
+	Sink       Handle
+	Segments   []string     // the names of the segments inside the bubble (not the source or sink), in topological order
+	Parent     *Bubble      // the smallest bubble that this bubble is nested in, nil for a top level bubble
+	Children   []*Bubble    // the bubbles nested directly inside this bubble
+	Traversals []*Traversal // the paths and walks that go from the source to the sink
+	source     segID
+	sink       segID
+	first      int // the position of the source in the order the bubbles are found in
+	last       int // the position of the sink in the order the bubbles are found in
+}
+
+// A Traversal is a path or walk going through a bubble
+type Traversal struct {
+	Record  Record   // the *Path or *Walk
+	Start   int      // the index of the step where the traversal enters the bubble
+	Reverse bool     // true if the path or walk goes through the bubble from the sink to the source
+	Steps   []Handle // the steps between the source and the sink, oriented from the source to the sink
+}
+
+// Alleles returns the different routes taken through the bubble by its traversals, in the order they are first seen
+func (bubble *Bubble) Alleles() [][]Handle {
+	alleles := [][]Handle{}
+	seen := make(map[string]bool)
+	for _, traversal := range bubble.Traversals {
+		key := make([]string, len(traversal.Steps))
+		for i, h := range traversal.Steps {
+			key[i] = h.String()
+		}
+		if k := strings.Join(key, ","); !seen[k] {
+			seen[k] = true
+			alleles = append(alleles, traversal.Steps)
+		}
+	}
+	return alleles
+}
+
+/*
+Superbubbles finds the superbubbles of the GFA instance, in the topological order of their sources
+
+// segments are taken in the orientation chosen by TopologicalSort, so each bubble is found once, in the direction that it is read in the order
+
+// segments that are joined by self-loops or inversions can't be part of a bubble (as they may leave it on the other strand), and bubbles
+with nothing inside them (a single link from source to sink) are not reported
+
+// bubbles are nested when one is inside another, and the paths and walks through each bubble are given as its traversals
+
+// the bubbles are found and nested in a single pass over an order in which every superbubble is a run of consecutive segments (Gärtner
+et al., 2018), so the time taken grows linearly with the size of the graph, in parts of the graph that can only be reached through a
+cycle the order starts at the first segment in the topological order, and a bubble around that segment is not found
+*/
+func (gfa *GFA) Superbubbles() []*Bubble {
+	sorted := gfa.TopologicalSort()
+	children := make([][]segID, len(gfa.nodes))
+	parents := make([][]segID, len(gfa.nodes))
+	joined := make(map[[2]segID]bool)
+	for _, link := range sorted.links {
+		if key := [2]segID{link.from, link.to}; !joined[key] {
+			joined[key] = true
+			children[link.from] = append(children[link.from], link.to)
+			parents[link.to] = append(parents[link.to], link.from)
+		}
+	}
+	tangled := make([]bool, len(gfa.nodes))
+	for _, link := range append(append([]*Link(nil), sorted.SelfLoops...), sorted.Inversions...) {
+		tangled[link.from.id()], tangled[link.to.id()] = true, true
+	}
+	position := make([]int, len(gfa.nodes))
+	for i, id := range sorted.ids {
+		position[id] = i
+	}
+	order, at := bubbleOrder(sorted.ids, children, parents)
+	sinks := closingPositions(order, at, children, tangled, 1)
+	sources := closingPositions(order, at, parents, tangled, -1)
+	handle := func(id segID) Handle {
+		return Handle{Name: gfa.names.name(id), Reverse: sorted.reverse[id]}
+	}
+	// a source and sink are a superbubble when the run from the source closes at the sink and the run back from the sink closes at the
+	// source, the bubbles that are still open when a bubble is found are the ones it is nested in
+	bubbles := []*Bubble{}
+	open := []*Bubble{}
+	for first, last := range sinks {
+		if last <= first+1 || sources[last] != first || joined[[2]segID{order[last], order[first]}] {
+			continue
+		}
+		source, sink := order[first], order[last]
+		bubble := &Bubble{Source: handle(source), Sink: handle(sink), source: source, sink: sink, first: first, last: last}
+		for len(open) != 0 && open[len(open)-1].last <= first {
+			open = open[:len(open)-1]
+		}
+		if len(open) != 0 {
+			bubble.Parent = open[len(open)-1]
+		}
+		open = append(open, bubble)
+		inside := append([]segID(nil), order[first+1:last]...)
+		sort.Slice(inside, func(i, j int) bool {
+			return position[inside[i]] < position[inside[j]]
+		})
+		for _, id := range inside {
+			bubble.Segments = append(bubble.Segments, gfa.names.name(id))
+		}
+		bubbles = append(bubbles, bubble)
+	}
+	sort.Slice(bubbles, func(i, j int) bool {
+		return position[bubbles[i].source] < position[bubbles[j].source]
+	})
+	for _, bubble := range bubbles {
+		if bubble.Parent != nil {
+			bubble.Parent.Children = append(bubble.Parent.Children, bubble)
+		}
+	}
+	gfa.traverseBubbles(bubbles, sorted.reverse, at)
+	return bubbles
+}
+
+// bubbleOrder returns the segment IDs in the reverse postorder of a depth-first search along the links, along with the position of each
+// segment ID in it (-1 for IDs with no segment), the search starts from the segments with no links into them and then from any segments
+// left over (in the topological order), it can only enter a superbubble through its source so the bubble is a run of the order
+func bubbleOrder(ids []segID, children, parents [][]segID) ([]segID, []int) {
+	type frame struct {
+		id   segID
+		next int // the next child to visit
+	}
+	visited := make([]bool, len(children))
+	postorder := make([]segID, 0, len(ids))
+	roots := make([]segID, 0, 2*len(ids))
+	for _, id := range ids {
+		if len(parents[id]) == 0 {
+			roots = append(roots, id)
+		}
+	}
+	for _, root := range append(roots, ids...) {
+		if visited[root] {
+			continue
+		}
+		visited[root] = true
+		stack := []frame{{id: root}}
+		for len(stack) != 0 {
+			top := &stack[len(stack)-1]
+			if top.next == len(children[top.id]) {
+				postorder = append(postorder, top.id)
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			child := children[top.id][top.next]
+			top.next++
+			if !visited[child] {
+				visited[child] = true
+				stack = append(stack, frame{id: child})
+			}
+		}
+	}
+	order := make([]segID, len(postorder))
+	at := make([]int, len(children))
+	for i := range at {
+		at[i] = -1
+	}
+	for i, id := range postorder {
+		order[len(order)-1-i] = id
+		at[id] = len(order) - 1 - i
+	}
+	return order, at
+}
+
+// closingPositions returns, for each position in the order, the nearest position in the given direction (1 for later, -1 for earlier)
+// that closes the run of segments from it: every segment of the run other than the closing one is untangled and has links in that
+// direction, which all go to segments further along the run (-1 if no position closes the run)
+//
+// the positions are worked out from the far end of the order, keeping a stack of the positions that close the runs from the position
+// before, nearest first, so each position is pushed and popped once
+func closingPositions(order []segID, at []int, next [][]segID, tangled []bool, direction int) []int {
+	closing := make([]int, len(order))
+	chain := []int{}
+	start := len(order) - 1
+	if direction < 0 {
+		start = 0
+	}
+	for i := start; i >= 0 && i < len(order); i -= direction {
+		id := order[i]
+		closing[i] = -1
+		// furthest is the position of the furthest linked segment, which the run has to reach
+		furthest, ok := i, len(next[id]) != 0 && !tangled[id]
+		for _, u := range next[id] {
+			if (at[u]-i)*direction <= 0 {
+				ok = false
+			}
+			if (at[u]-furthest)*direction > 0 {
+				furthest = at[u]
+			}
+		}
+		if !ok {
+			chain = chain[:0]
+		}
+		for ok && len(chain) != 0 && (furthest-chain[len(chain)-1])*direction > 0 {
+			chain = chain[:len(chain)-1]
+		}
+		if ok && len(chain) != 0 {
+			closing[i] = chain[len(chain)-1]
+		}
+		if ok && closing[i] == -1 {
+			chain = chain[:0]
+		}
+		chain = append(chain, i)
+	}
+	return closing
+}
+
+// traverseBubbles adds the traversals of the paths and walks of the GFA instance to the bubbles
+func (gfa *GFA) traverseBubbles(bubbles []*Bubble, reverse []bool, at []int) {
+	bySource := make(map[segID]*Bubble)
+	bySink := make(map[segID]*Bubble)
+	for _, bubble := range bubbles {
+		bySource[bubble.source] = bubble
+		bySink[bubble.sink] = bubble
+	}
+	traverse := func(record Record, steps []step) {
+		for i, s := range steps {
+			if bubble, ok := bySource[s.id()]; ok && s == newStep(bubble.source, reverse[bubble.source]) {
+				bubble.traverse(gfa.names, at, record, steps, i, newStep(bubble.sink, reverse[bubble.sink]), false)
+			}
+			if bubble, ok := bySink[s.id()]; ok && s == newStep(bubble.sink, !reverse[bubble.sink]) {
+				bubble.traverse(gfa.names, at, record, steps, i, newStep(bubble.source, !reverse[bubble.source]), true)
+			}
+		}
+	}
+	for _, path := range gfa.paths {
+		traverse(path, path.steps)
+	}
+	for _, walk := range gfa.walks {
+		traverse(walk, walk.steps)
+	}
+}
+
+// traverse adds a traversal of the bubble if the steps from start stay inside the bubble (between the positions of its source and sink in
+// the order the bubbles were found in) until they reach the end step
+func (bubble *Bubble) traverse(names *nameTable, at []int, record Record, steps []step, start int, end step, reverse bool) {
+	for i := start + 1; i < len(steps); i++ {
+		if steps[i] == end {
+			traversal := &Traversal{Record: record, Start: start, Reverse: reverse, Steps: []Handle{}}
+			for _, s := range steps[start+1 : i] {
+				if reverse {
+					s = s.flip()
+				}
+				traversal.Steps = append(traversal.Steps, names.handle(s))
+			}
+			if reverse {
+				for l, r := 0, len(traversal.Steps)-1; l < r; l, r = l+1, r-1 {
+					traversal.Steps[l], traversal.Steps[r] = traversal.Steps[r], traversal.Steps[l]
+				}
+			}
+			bubble.Traversals = append(bubble.Traversals, traversal)
+			return
+		}
+		if p := at[steps[i].id()]; p <= bubble.first || p >= bubble.last {
+			return
+		}
+	}
+}
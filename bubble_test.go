@@ -0,0 +1,115 @@
+package gfa
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var bubbleInput = `H	VN:Z:1
+S	1	A
+S	2	C
+S	3	G
+S	4	T
+S	5	A
+S	6	C
+S	7	G
+S	8	T
+S	9	A
+S	10	C
+L	1	+	2	+	0M
+L	1	+	3	+	0M
+L	2	+	4	+	0M
+L	3	+	4	+	0M
+L	4	+	5	+	0M
+L	5	+	6	+	0M
+L	7	-	5	-	0M
+L	6	+	8	+	0M
+L	7	+	8	+	0M
+L	8	+	9	+	0M
+L	4	+	9	+	0M
+L	9	+	10	+	0M
+L	10	+	10	+	0M
+P	p1	1+,2+,4+,5+,6+,8+,9+	*
+P	p2	9-,8-,7-,5-,4-,3-,1-	*
+P	p3	1+,3+,4+,9+,10+	*
+`
+
+// test that nested superbubbles are found with the alleles taken by each path
+func TestSuperbubbles(t *testing.T) {
+	myGFA := readTestGFA(t, bubbleInput)
+	bubbles := myGFA.Superbubbles()
+	found := []string{}
+	for _, bubble := range bubbles {
+		found = append(found, fmt.Sprintf("%v-%v%v", bubble.Source, bubble.Sink, bubble.Segments))
+	}
+	// 10 has a self-loop, so 9 to 10 is not a bubble
+	if fmt.Sprint(found) != "[1+-4+[2 3] 4+-9+[5 6 7 8] 5+-8+[6 7]]" {
+		t.Fatalf("unexpected bubbles: %v", found)
+	}
+	if bubbles[2].Parent != bubbles[1] || len(bubbles[1].Children) != 1 || bubbles[0].Parent != nil {
+		t.Fatal("bubbles were not nested")
+	}
+	if fmt.Sprint(bubbles[0].Alleles()) != "[[2+] [3+]]" || fmt.Sprint(bubbles[1].Alleles()) != "[[5+ 6+ 8+] [5+ 7+ 8+] []]" {
+		t.Fatalf("unexpected alleles: %v %v", bubbles[0].Alleles(), bubbles[1].Alleles())
+	}
+	// p2 goes through the bubbles on the reverse strand
+	traversal := bubbles[2].Traversals[1]
+	if string(traversal.Record.(*Path).GetName()) != "p2" || !traversal.Reverse || traversal.Start != 1 {
+		t.Fatalf("unexpected traversal: %+v", traversal)
+	}
+}
+
+// test that every bubble in a graph built from an MSA is traversed by the sequences of the MSA
+func TestMSASuperbubbles(t *testing.T) {
+	msa, err := ReadMSA(testMSAfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	myGFA, err := MSA2GFA(msa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bubbles := myGFA.Superbubbles()
+	if len(bubbles) == 0 {
+		t.Fatal("no bubbles found in MSA graph")
+	}
+	for _, bubble := range bubbles {
+		if len(bubble.Alleles()) < 2 {
+			t.Fatalf("bubble %v-%v has fewer than 2 alleles: %v", bubble.Source, bubble.Sink, bubble.Alleles())
+		}
+	}
+}
+
+// test that a long run of bubbles, each with another bubble nested inside it, is found and nested in order
+func TestNestedSuperbubbles(t *testing.T) {
+	var input strings.Builder
+	input.WriteString("H\tVN:Z:1\n")
+	// bubble n goes from a_n to a_n+1 through c_n or through the nested bubble from i_n to j_n, which goes through d_n or e_n
+	count := 10000
+	for n := 0; n <= count; n++ {
+		fmt.Fprintf(&input, "S\ta%d\tA\n", n)
+	}
+	for n := 0; n < count; n++ {
+		for _, name := range []string{"c", "i", "j", "d", "e"} {
+			fmt.Fprintf(&input, "S\t%v%d\tC\n", name, n)
+		}
+		for _, link := range [][2]string{{"a", "c"}, {"a", "i"}, {"i", "d"}, {"i", "e"}, {"d", "j"}, {"e", "j"}} {
+			fmt.Fprintf(&input, "L\t%v%d\t+\t%v%d\t+\t0M\n", link[0], n, link[1], n)
+		}
+		fmt.Fprintf(&input, "L\tc%d\t+\ta%d\t+\t0M\nL\tj%d\t+\ta%d\t+\t0M\n", n, n+1, n, n+1)
+	}
+	bubbles := readTestGFA(t, input.String()).Superbubbles()
+	if len(bubbles) != 2*count {
+		t.Fatalf("expected %d bubbles, got %d", 2*count, len(bubbles))
+	}
+	for n := 0; n < count; n++ {
+		outer, inner := bubbles[2*n], bubbles[2*n+1]
+		if found := fmt.Sprintf("%v-%v%v %v-%v%v", outer.Source, outer.Sink, len(outer.Segments), inner.Source, inner.Sink, inner.Segments); found != fmt.Sprintf("a%d+-a%d+5 i%d+-j%d+[d%d e%d]", n, n+1, n, n, n, n) {
+			t.Fatalf("unexpected bubbles: %v", found)
+		}
+		if outer.Parent != nil || inner.Parent != outer || len(outer.Children) != 1 || len(inner.Children) != 0 {
+			t.Fatalf("bubble %v-%v was not nested", inner.Source, inner.Sink)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package gfa
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// cigarOps are the operations allowed in a CIGAR
+const cigarOps = "MIDNSHP=X"
+
+// A CIGAROp is a single operation of a CIGAR, such as the 55M in 55M2I3M
+type CIGAROp struct {
+	Length int
+	Op     byte // one of M, I, D, N, S, H, P, = or X
+}
+
+// A CIGAR describes the alignment between two sequences, for an overlap the reference is the first segment and the query is the second
+// (an empty CIGAR is written as *, meaning the overlap is not given)
+type CIGAR []CIGAROp
+
+// ParseCIGAR parses a CIGAR string, * gives an empty CIGAR
+func ParseCIGAR(cigar string) (CIGAR, error) {
+	if cigar == "*" {
+		return nil, nil
+	}
+	if cigar == "" {
+		return nil, fmt.Errorf("CIGAR can't be empty (use * if there is no CIGAR)")
+	}
+	ops := CIGAR{}
+	start := 0
+	for i := 0; i < len(cigar); i++ {
+		if cigar[i] >= '0' && cigar[i] <= '9' {
+			continue
+		}
+		if i == start {
+			return nil, fmt.Errorf("CIGAR operation has no length: %v", cigar)
+		}
+		length, err := strconv.Atoi(cigar[start:i])
+		if err != nil {
+			return nil, fmt.Errorf("CIGAR operation has a bad length: %v", cigar)
+		}
+		ops = append(ops, CIGAROp{Length: length, Op: cigar[i]})
+		start = i + 1
+	}
+	if start != len(cigar) {
+		return nil, fmt.Errorf("CIGAR has a trailing length with no operation: %v", cigar)
+	}
+	if err := ops.Validate(); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// Validate checks that each operation of a CIGAR is known and has a length
+func (c CIGAR) Validate() error {
+	for _, op := range c {
+		if strings.IndexByte(cigarOps, op.Op) == -1 {
+			return fmt.Errorf("unknown CIGAR operation: %q", op.Op)
+		}
+		if op.Length < 0 {
+			return fmt.Errorf("CIGAR operation %q has a negative length: %d", op.Op, op.Length)
+		}
+	}
+	return nil
+}
+
+// String formats a CIGAR, an empty CIGAR is *
+func (c CIGAR) String() string {
+	if len(c) == 0 {
+		return "*"
+	}
+	var b strings.Builder
+	for _, op := range c {
+		b.WriteString(strconv.Itoa(op.Length))
+		b.WriteByte(op.Op)
+	}
+	return b.String()
+}
+
+// ReferenceLength returns the number of bases of the reference (the first segment of an overlap) consumed by a CIGAR
+func (c CIGAR) ReferenceLength() int {
+	length := 0
+	for _, op := range c {
+		switch op.Op {
+		case 'M', '=', 'X', 'D', 'N':
+			length += op.Length
+		}
+	}
+	return length
+}
+
+// QueryLength returns the number of bases of the query (the second segment of an overlap) consumed by a CIGAR
+func (c CIGAR) QueryLength() int {
+	length := 0
+	for _, op := range c {
+		switch op.Op {
+		case 'M', '=', 'X', 'I', 'S':
+			length += op.Length
+		}
+	}
+	return length
+}
+
+// Reverse returns a CIGAR with its operations in reverse order, as needed when traversing an alignment from the other strand
+func (c CIGAR) Reverse() CIGAR {
+	reversed := make(CIGAR, len(c))
+	for i, op := range c {
+		reversed[len(c)-1-i] = op
+	}
+	return reversed
+}
+
+// Invert returns a CIGAR with the reference and query swapped, so that insertions become deletions and vice versa
+func (c CIGAR) Invert() CIGAR {
+	inverted := make(CIGAR, len(c))
+	for i, op := range c {
+		switch op.Op {
+		case 'I':
+			op.Op = 'D'
+		case 'D':
+			op.Op = 'I'
+		}
+		inverted[i] = op
+	}
+	return inverted
+}
+
+// GetCIGAR returns the overlap of a link as a CIGAR (empty if the overlap is *)
+func (link *Link) GetCIGAR() (CIGAR, error) {
+	return ParseCIGAR(link.overlap)
+}
+
+// GetCIGAR returns the overlap of a containment as a CIGAR (empty if the overlap is *)
+func (containment *Containment) GetCIGAR() (CIGAR, error) {
+	return ParseCIGAR(containment.overlap)
+}
+
+// GetCIGARs returns the overlaps of a path as CIGARs, an overlap given as * is an empty CIGAR (nil if the path has no overlaps)
+func (path *Path) GetCIGARs() ([]CIGAR, error) {
+	if path.overlaps == "*" {
+		return nil, nil
+	}
+	overlaps := path.GetOverlaps()
+	cigars := make([]CIGAR, len(overlaps))
+	for i, overlap := range overlaps {
+		cigar, err := ParseCIGAR(overlap)
+		if err != nil {
+			return nil, err
+		}
+		cigars[i] = cigar
+	}
+	return cigars, nil
+}
+
+// overlapLength returns the number of bases at the start of the second of two consecutive steps that are overlapped by the first,
+// using the given overlap or, if that is *, the overlap of the link between the steps (read in either direction)
+func (gfa *GFA) overlapLength(overlap string, from, to step) (int, error) {
+	if overlap == "*" {
+		overlap = gfa.linkOverlap(from, to)
+	}
+	cigar, err := ParseCIGAR(overlap)
+	if err != nil {
+		return 0, fmt.Errorf("Overlap between %v and %v is not a valid CIGAR: %v", gfa.names.handle(from), gfa.names.handle(to), err)
+	}
+	return cigar.QueryLength(), nil
+}
+
+// linkOverlap returns the overlap of the link from one step to the next, which is inverted if the link is written in the other direction
+// (* if there is no link)
+func (gfa *GFA) linkOverlap(from, to step) string {
+	for _, link := range gfa.nodes[from.id()].linksFrom[from&1] {
+		if link.to == to {
+			return link.overlap
+		}
+	}
+	for _, link := range gfa.nodes[to.id()].linksFrom[to.flip()&1] {
+		if link.to == from.flip() {
+			return invertCIGAR(reverseCIGAR(link.overlap))
+		}
+	}
+	return "*"
+}
+
+// cigarLengths returns the number of reference (first segment) and query (second segment) bases consumed by a CIGAR
+func cigarLengths(cigar string) (int, int, error) {
+	if cigar == "" || cigar == "*" {
+		return 0, 0, fmt.Errorf("no CIGAR supplied")
+	}
+	c, err := ParseCIGAR(cigar)
+	if err != nil {
+		return 0, 0, err
+	}
+	return c.ReferenceLength(), c.QueryLength(), nil
+}
+
+// reverseCIGAR reverses the order of the operations in a CIGAR string, anything that is not a CIGAR is returned unchanged
+func reverseCIGAR(cigar string) string {
+	c, err := ParseCIGAR(cigar)
+	if err != nil || c == nil {
+		return cigar
+	}
+	return c.Reverse().String()
+}
+
+// invertCIGAR swaps the reference and query of a CIGAR string, anything that is not a CIGAR is returned unchanged
+func invertCIGAR(cigar string) string {
+	c, err := ParseCIGAR(cigar)
+	if err != nil || c == nil {
+		return cigar
+	}
+	return c.Invert().String()
+}
@@ -0,0 +1,78 @@
+package gfa
+
+import (
+	"testing"
+)
+
+// test CIGAR parsing, formatting and lengths
+func TestParseCIGAR(t *testing.T) {
+	cigar, err := ParseCIGAR("55M2I3D10=1X")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cigar) != 5 || cigar[1] != (CIGAROp{Length: 2, Op: 'I'}) {
+		t.Fatalf("unexpected CIGAR operations: %v", cigar)
+	}
+	if cigar.String() != "55M2I3D10=1X" || cigar.ReferenceLength() != 69 || cigar.QueryLength() != 68 {
+		t.Fatalf("unexpected CIGAR lengths: %v %d %d", cigar, cigar.ReferenceLength(), cigar.QueryLength())
+	}
+	if reversed := cigar.Reverse().Invert(); reversed.String() != "1X10=3I2D55M" || reversed.QueryLength() != cigar.ReferenceLength() {
+		t.Fatalf("unexpected reversed CIGAR: %v", reversed)
+	}
+	if empty, err := ParseCIGAR("*"); err != nil || empty != nil || empty.String() != "*" {
+		t.Fatal("* should parse as an empty CIGAR")
+	}
+	for _, bad := range []string{"", "M", "10", "5M3", "4Q", "2M-1I"} {
+		if _, err := ParseCIGAR(bad); err == nil {
+			t.Fatalf("bad CIGAR should not parse: %q", bad)
+		}
+	}
+	if err := (CIGAR{{Length: -1, Op: 'M'}}).Validate(); err == nil {
+		t.Fatal("CIGAR with a negative length should not validate")
+	}
+}
+
+var overlapInput = `H	VN:Z:1
+S	1	ACGTAC
+S	2	TACGG
+S	3	GGTTT
+L	1	+	2	+	3M
+L	3	-	2	-	2M
+P	p1	1+,2+,3+	*
+P	p2	1+,2+,3+	3M,0M
+P	p3	1+,2+,3+	*,1M1I1M
+P	p4	1+,2+	7M
+W	s1	0	chr1	*	*	>1>2>3
+W	s1	1	chr1	*	*	<3<2<1
+`
+
+// test that overlapping bases are only spelled once, using the path overlaps or the link overlaps
+func TestOverlapSpelling(t *testing.T) {
+	myGFA := readTestGFA(t, overlapInput)
+	for name, expected := range map[string]string{"p1": "ACGTACGGTTT", "p2": "ACGTACGGGGTTT", "p3": "ACGTACGGTT"} {
+		seq, err := myGFA.PrintSequence([]byte(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(seq) != expected {
+			t.Fatalf("path %v spelled %v, expected %v", name, string(seq), expected)
+		}
+	}
+	if _, err := myGFA.PrintSequence([]byte("p4")); err == nil {
+		t.Fatal("overlap longer than the segment should fail")
+	}
+	for hap, expected := range []string{"ACGTACGGTTT", "AAACCGTACGT"} {
+		seq, err := myGFA.PrintWalkSequence([]byte("s1"), hap, []byte("chr1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(seq) != expected {
+			t.Fatalf("walk spelled %v, expected %v", string(seq), expected)
+		}
+	}
+	path, _ := myGFA.GetPath([]byte("p3"))
+	cigars, err := path.GetCIGARs()
+	if err != nil || len(cigars) != 2 || cigars[0] != nil || cigars[1].String() != "1M1I1M" {
+		t.Fatalf("unexpected path CIGARs: %v", cigars)
+	}
+}
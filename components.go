@@ -0,0 +1,182 @@
+package gfa
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ConnectedComponents returns the segments of each weakly connected component of the GFA instance, where segments are connected by
+// links and containments (or edges in GFA2) whatever their orientation, the components are in the order of their first segment
+func (gfa *GFA) ConnectedComponents() [][]*Segment {
+	labels, count := gfa.componentLabels()
+	components := make([][]*Segment, count)
+	for _, seg := range gfa.segments {
+		components[labels[seg.id]] = append(components[labels[seg.id]], seg)
+	}
+	return components
+}
+
+// componentLabels numbers the connected components of the GFA instance and returns the component of each segment ID
+// (-1 for IDs that have been referenced but have no segment)
+func (gfa *GFA) componentLabels() ([]int, int) {
+	parent := make([]segID, len(gfa.nodes))
+	for i := range parent {
+		parent[i] = segID(i)
+	}
+	find := func(id segID) segID {
+		for parent[id] != id {
+			parent[id] = parent[parent[id]]
+			id = parent[id]
+		}
+		return id
+	}
+	// records that reference a missing segment don't connect anything
+	union := func(a, b segID) {
+		if gfa.segment(a) != nil && gfa.segment(b) != nil {
+			parent[find(a)] = find(b)
+		}
+	}
+	for _, link := range gfa.links {
+		union(link.from.id(), link.to.id())
+	}
+	for _, containment := range gfa.containments {
+		union(containment.container.id(), containment.contained.id())
+	}
+	for _, edge := range gfa.edges {
+		sid1, ok1 := gfa.lookup(edge.Sid1)
+		sid2, ok2 := gfa.lookup(edge.Sid2)
+		if ok1 && ok2 {
+			union(sid1, sid2)
+		}
+	}
+	labels := make([]int, len(gfa.nodes))
+	for i := range labels {
+		labels[i] = -1
+	}
+	roots := make(map[segID]int)
+	for _, seg := range gfa.segments {
+		root := find(seg.id)
+		if _, ok := roots[root]; !ok {
+			roots[root] = len(roots)
+		}
+		labels[seg.id] = roots[root]
+	}
+	return labels, len(roots)
+}
+
+/*
+SplitComponents returns a new GFA instance for each connected component of a GFA version 1 instance, in the same order as ConnectedComponents
+
+// each GFA instance has a copy of the header and comments, the segments of the component and the links and containments between them
+
+// paths and walks are only kept if every segment they visit is in the component, as are jumps (which don't connect components)
+
+// the records are copied, so the new GFA instances can be changed without changing the original
+*/
+func (gfa *GFA) SplitComponents() ([]*GFA, error) {
+	if gfa.GetVersion() != 1 {
+		return nil, fmt.Errorf("Can only split a GFA version 1 instance into components")
+	}
+	labels, count := gfa.componentLabels()
+	parts := make([]*GFA, count)
+	for i := range parts {
+		parts[i] = gfa.emptyCopy()
+	}
+	// component returns the component that all of the steps are in, or -1 if they are not all in one component
+	component := func(steps ...step) int {
+		c := labels[steps[0].id()]
+		for _, s := range steps[1:] {
+			if labels[s.id()] != c {
+				return -1
+			}
+		}
+		return c
+	}
+	for _, seg := range gfa.segments {
+		if err := seg.clone().Add(parts[labels[seg.id]]); err != nil {
+			return nil, err
+		}
+	}
+	for _, link := range gfa.links {
+		if c := component(link.from, link.to); c != -1 {
+			clone := *link
+			clone.optional = link.optional.clone()
+			clone.Add(parts[c])
+		}
+	}
+	for _, containment := range gfa.containments {
+		if c := component(containment.container, containment.contained); c != -1 {
+			clone := *containment
+			clone.optional = containment.optional.clone()
+			clone.Add(parts[c])
+		}
+	}
+	for _, jump := range gfa.jumps {
+		if c := component(jump.from, jump.to); c != -1 {
+			clone := *jump
+			clone.optional = jump.optional.clone()
+			clone.Add(parts[c])
+		}
+	}
+	for _, path := range gfa.paths {
+		if len(path.steps) == 0 {
+			continue
+		}
+		if c := component(path.steps...); c != -1 {
+			clone := *path
+			clone.steps = append([]step(nil), path.steps...)
+			clone.optional = path.optional.clone()
+			clone.Add(parts[c])
+		}
+	}
+	for _, walk := range gfa.walks {
+		if len(walk.steps) == 0 {
+			continue
+		}
+		if c := component(walk.steps...); c != -1 {
+			clone := *walk
+			clone.steps = append([]step(nil), walk.steps...)
+			clone.optional = walk.optional.clone()
+			clone.Add(parts[c])
+		}
+	}
+	return parts, nil
+}
+
+// clone returns a copy of a segment that can be added to another GFA instance
+func (seg *Segment) clone() *Segment {
+	clone := *seg
+	clone.optional = seg.optional.clone()
+	return &clone
+}
+
+/*
+WriteComponents writes each GFA instance (e.g. from SplitComponents) to its own file, returning the names of the files
+
+// the file names are made by replacing %d in fileName with the number of the component, starting at 1 (e.g. chr.%d.gfa)
+
+// the files are BGZF compressed if fileName ends with .gz, any WriterOptions are applied to every file
+*/
+func WriteComponents(components []*GFA, fileName string, opts ...WriterOption) ([]string, error) {
+	if strings.Count(fileName, "%d") != 1 {
+		return nil, fmt.Errorf("File name must contain %%d once, to number the components: %v", fileName)
+	}
+	fileNames := []string{}
+	for i, component := range components {
+		name := strings.Replace(fileName, "%d", strconv.Itoa(i+1), 1)
+		writer, err := Create(name, component, opts...)
+		if err != nil {
+			return nil, err
+		}
+		if err := component.WriteGFAContent(writer); err != nil {
+			writer.Close()
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		fileNames = append(fileNames, name)
+	}
+	return fileNames, nil
+}
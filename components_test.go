@@ -0,0 +1,96 @@
+package gfa
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var componentsInput = `H	VN:Z:1
+# two plasmids
+S	1	ACGT	KC:i:5
+S	2	TT
+S	3	GGA
+S	4	C
+S	5	AAAA
+S	6	G
+L	1	+	2	-	0M
+L	3	-	2	+	0M
+C	5	+	6	+	1	1M
+J	1	+	4	+	100
+P	p1	1+,2-,3+	*
+P	p2	5+	*
+P	p3	1+,4+	*
+W	s1	0	chr1	*	*	>4
+`
+
+// test that segments are grouped into weakly connected components
+func TestConnectedComponents(t *testing.T) {
+	myGFA := readTestGFA(t, componentsInput)
+	components := myGFA.ConnectedComponents()
+	expected := [][]string{{"1", "2", "3"}, {"4"}, {"5", "6"}}
+	if len(components) != len(expected) {
+		t.Fatalf("expected %d components, got %d", len(expected), len(components))
+	}
+	for i, component := range components {
+		if len(component) != len(expected[i]) {
+			t.Fatalf("component %d has %d segments, expected %d", i, len(component), len(expected[i]))
+		}
+		for j, seg := range component {
+			if string(seg.GetName()) != expected[i][j] {
+				t.Fatalf("unexpected segment %v in component %d", string(seg.GetName()), i)
+			}
+		}
+	}
+}
+
+// test that each component is split into its own GFA instance, and can be written to its own file
+func TestSplitComponents(t *testing.T) {
+	myGFA := readTestGFA(t, componentsInput)
+	parts, err := myGFA.SplitComponents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"H\tVN:Z:1\n# two plasmids\nS\t1\tACGT\tLN:i:4\tKC:i:5\nS\t2\tTT\tLN:i:2\nS\t3\tGGA\tLN:i:3\nL\t1\t+\t2\t-\t0M\nL\t3\t-\t2\t+\t0M\nP\tp1\t1+,2-,3+\t*\n",
+		"H\tVN:Z:1\n# two plasmids\nS\t4\tC\tLN:i:1\nW\ts1\t0\tchr1\t*\t*\t>4\n",
+		"H\tVN:Z:1\n# two plasmids\nS\t5\tAAAA\tLN:i:4\nS\t6\tG\tLN:i:1\nC\t5\t+\t6\t+\t1\t1M\nP\tp2\t5+\t*\n",
+	}
+	if len(parts) != len(expected) {
+		t.Fatalf("expected %d GFA instances, got %d", len(expected), len(parts))
+	}
+	for i, part := range parts {
+		if output := string(writeGFA(t, part)); output != expected[i] {
+			t.Fatalf("unexpected GFA for component %d:\n%v", i, output)
+		}
+	}
+	// the parts don't share records with the original
+	seg, _ := parts[0].GetSegment([]byte("1"))
+	if err := seg.GetOptionalFields().SetInt("KC", 10); err != nil {
+		t.Fatal(err)
+	}
+	original, _ := myGFA.GetSegment([]byte("1"))
+	if kc, _ := original.GetKmerCount(); kc != 5 {
+		t.Fatal("changing a component changed the original GFA instance")
+	}
+	dir, err := ioutil.TempDir("", "gfa-components")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fileNames, err := WriteComponents(parts, filepath.Join(dir, "part.%d.gfa"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fileNames) != 3 || fileNames[2] != filepath.Join(dir, "part.3.gfa") {
+		t.Fatalf("unexpected file names: %v", fileNames)
+	}
+	content, err := ioutil.ReadFile(fileNames[1])
+	if err != nil || string(content) != expected[1] {
+		t.Fatalf("unexpected file content:\n%v", string(content))
+	}
+	if _, err := WriteComponents(parts, filepath.Join(dir, "part.gfa")); err == nil {
+		t.Fatal("file name without a component number should fail")
+	}
+}
@@ -0,0 +1,150 @@
+package gfa
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+)
+
+// Compression is the compression format of a GFA file
+type Compression int
+
+// The compression formats that can be read by Open
+const (
+	Uncompressed Compression = iota
+	Gzip
+	BGZF // blocked gzip, as used by bgzip and htslib
+)
+
+// String returns the name of a compression format
+func (c Compression) String() string {
+	switch c {
+	case Gzip:
+		return "gzip"
+	case BGZF:
+		return "BGZF"
+	}
+	return "uncompressed"
+}
+
+// detectCompression uses the magic bytes at the start of a file to work out its compression format
+func detectCompression(magic []byte) Compression {
+	if len(magic) < 2 || magic[0] != 0x1f || magic[1] != 0x8b {
+		return Uncompressed
+	}
+	// BGZF blocks are gzip members with a BC extra subfield
+	if len(magic) >= 14 && magic[3]&0x04 != 0 && bytes.Equal(magic[12:14], []byte("BC")) {
+		return BGZF
+	}
+	return Gzip
+}
+
+// Open opens a GFA file for reading, which can be uncompressed, gzip or BGZF compressed (detected from the magic bytes)
+//
+// the Reader should be closed once finished with
+func Open(fileName string, opts ...ReaderOption) (*Reader, error) {
+	fh, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(fh)
+	magic, err := br.Peek(14)
+	if err != nil && err != io.EOF {
+		fh.Close()
+		return nil, err
+	}
+	compression := detectCompression(magic)
+	var r io.Reader = br
+	closers := []io.Closer{fh}
+	if compression != Uncompressed {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			fh.Close()
+			return nil, err
+		}
+		r = gz
+		closers = append([]io.Closer{gz}, closers...)
+	}
+	reader, err := NewReader(r, opts...)
+	if err != nil {
+		for _, c := range closers {
+			c.Close()
+		}
+		return nil, err
+	}
+	reader.compression = compression
+	reader.closers = closers
+	reader.file = fh
+	return reader, nil
+}
+
+// Compression returns the compression format of the file opened by Open (Uncompressed for a Reader created by NewReader)
+func (r *Reader) Compression() Compression {
+	return r.compression
+}
+
+// Close closes the file opened by Open, it does nothing for a Reader created by NewReader
+func (r *Reader) Close() error {
+	var err error
+	for _, c := range r.closers {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	r.closers = nil
+	return err
+}
+
+// WriterOption is a function that sets an option on a GFAwriter
+type WriterOption func(*GFAwriter)
+
+// BGZFCompress sets a GFAwriter to compress its output into BGZF blocks, which can be read by gzip and indexed by htslib tools
+// (the output is only compressed once, however many times the option is given)
+func BGZFCompress() WriterOption {
+	return func(writer *GFAwriter) {
+		if writer.bgzf != nil {
+			return
+		}
+		writer.bgzf = newBGZFWriter(writer.w)
+		writer.w = writer.bgzf
+	}
+}
+
+// Create creates a GFA file and returns a GFAwriter for it, the output is BGZF compressed if the file name ends with .gz
+//
+// the GFAwriter must be closed once finished with
+func Create(fileName string, myGFA *GFA, opts ...WriterOption) (*GFAwriter, error) {
+	fh, err := os.Create(fileName)
+	if err != nil {
+		return nil, err
+	}
+	// a .gz file is compressed whether or not BGZFCompress is also given
+	if strings.HasSuffix(fileName, ".gz") {
+		opts = append([]WriterOption{BGZFCompress()}, opts...)
+	}
+	writer, err := NewWriter(fh, myGFA, opts...)
+	if err != nil {
+		fh.Close()
+		return nil, err
+	}
+	writer.closer = fh
+	return writer, nil
+}
+
+// Close finishes any compressed output and closes the file created by Create, it must be called for a BGZF compressed GFAwriter
+func (myWriter *GFAwriter) Close() error {
+	var err error
+	if myWriter.bgzf != nil {
+		err = myWriter.bgzf.Close()
+	}
+	if myWriter.closer != nil {
+		if cerr := myWriter.closer.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		myWriter.closer = nil
+	}
+	return err
+}
@@ -0,0 +1,139 @@
+package gfa
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// test writing and reading back BGZF, gzip and uncompressed GFA files
+func TestOpenCreate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gfa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	input, err := ioutil.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	myGFA := readLossless(t, input)
+
+	// write a BGZF file
+	bgzfFile := filepath.Join(dir, "example.gfa.gz")
+	writer, err := Create(bgzfFile, myGFA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := myGFA.WriteGFAContent(writer); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	compressed, err := ioutil.ReadFile(bgzfFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasSuffix(compressed, bgzfEOF) {
+		t.Fatal("BGZF file does not end with the EOF block")
+	}
+
+	// write a gzip file
+	gzipFile := filepath.Join(dir, "gzip.gfa.gz")
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(input)
+	gz.Close()
+	if err := ioutil.WriteFile(gzipFile, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// read them all back
+	for fileName, compression := range map[string]Compression{bgzfFile: BGZF, gzipFile: Gzip, testFile: Uncompressed} {
+		reader, err := Open(fileName, Lossless())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reader.Compression() != compression {
+			t.Fatalf("%v detected as %v, not %v", fileName, reader.Compression(), compression)
+		}
+		readGFA, err := reader.ReadAll(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := reader.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(writeGFA(t, readGFA), input) {
+			t.Fatalf("%v did not give back the original GFA", fileName)
+		}
+	}
+}
+
+// test that data spanning several BGZF blocks can be decompressed by gzip
+func TestBGZFBlocks(t *testing.T) {
+	data := bytes.Repeat([]byte("S\t1\tACGTACGTTGCA\n"), 10000)
+	var buf bytes.Buffer
+	bw := newBGZFWriter(&buf)
+	if _, err := bw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if bw.VirtualOffset()>>16 == 0 {
+		t.Fatal("expected more than one block to be written")
+	}
+	if err := bw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if detectCompression(buf.Bytes()) != BGZF {
+		t.Fatal("BGZF output not detected as BGZF")
+	}
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decompressed, err := ioutil.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, decompressed) {
+		t.Fatal("BGZF blocks did not decompress to the original data")
+	}
+}
+
+// test that a .gz file is only compressed once when BGZFCompress is also given
+func TestCreateBGZFOption(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gfa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	input := []byte("H\tVN:Z:1\nS\t1\tACGT\nS\t2\tTT\nL\t1\t+\t2\t+\t0M\n")
+	myGFA := readLossless(t, input)
+	fileName := filepath.Join(dir, "x.gfa.gz")
+	writer, err := Create(fileName, myGFA, BGZFCompress())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := myGFA.WriteGFAContent(writer); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := Open(fileName, Lossless())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	readGFA, err := reader.ReadAll(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output := writeGFA(t, readGFA); !bytes.Equal(output, input) {
+		t.Fatalf("BGZF file did not give back the original GFA: %q", output)
+	}
+}
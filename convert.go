@@ -0,0 +1,383 @@
+package gfa
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// A ConversionWarning records information that could not be represented when converting between GFA versions
+type ConversionWarning struct {
+	RecordType string // the type of the record that could not be fully converted
+	Record     string // the GFA formatted line of the record
+	Reason     string
+}
+
+// String prints the conversion warning
+func (cw *ConversionWarning) String() string {
+	return fmt.Sprintf("%v record not fully converted (%v): %v", cw.RecordType, cw.Reason, cw.Record)
+}
+
+/*
+ConvertToGFA2 returns a new GFA2 instance containing the content of a GFA1 instance
+
+// segments are given an explicit length, replacing any LN tag
+
+// links and containments are converted to edges, with positions calculated from the segment lengths and CIGAR overlaps (an ID tag becomes the edge identifier)
+
+// paths are converted to ordered groups
+
+// jumps are converted to gaps
+
+// anything that can't be represented in GFA2 (e.g. walks) is reported as a ConversionWarning
+*/
+func ConvertToGFA2(gfa *GFA) (*GFA, []*ConversionWarning, error) {
+	if gfa.GetVersion() != 1 {
+		return nil, nil, fmt.Errorf("Can only convert a GFA version 1 instance to GFA2")
+	}
+	if err := gfa.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("GFA validation failed, can't convert: %v", err)
+	}
+	warnings := []*ConversionWarning{}
+	warn := func(line Record, recordType, reason string) {
+		warnings = append(warnings, &ConversionWarning{RecordType: recordType, Record: line.PrintGFAline(), Reason: reason})
+	}
+	newGFA := NewGFA()
+	err := newGFA.AddVersion(2)
+	if err != nil {
+		return nil, nil, err
+	}
+	newGFA.comments = append(newGFA.comments, gfa.comments...)
+	lengths := make(map[segID]int)
+	for _, seg := range gfa.segments {
+		// segments without a sequence take their length from the LN tag
+		length := seg.Length
+		if seg.seq.isPlaceholder() && seg.optional.Has("LN") {
+			if length, err = seg.optional.GetInt("LN"); err != nil {
+				return nil, nil, fmt.Errorf("Can't convert segment %v: %v", string(seg.GetName()), err)
+			}
+		}
+		newSeg, err := NewGFA2Segment(seg.GetName(), []byte(strconv.Itoa(length)), seg.GetSequence())
+		if err != nil {
+			return nil, nil, err
+		}
+		newSeg.optional = seg.optional.clone()
+		newSeg.optional.Remove("LN")
+		if err := newSeg.Add(newGFA); err != nil {
+			return nil, nil, err
+		}
+		lengths[seg.id] = length
+	}
+	// a dovetail overlap runs from the end of the From segment into the start of the To segment
+	for _, link := range gfa.links {
+		// an unknown overlap (*) is carried over as it is
+		refLen, queryLen, err := cigarLengths(link.overlap)
+		if err != nil && link.overlap != "*" {
+			warn(link, "L", "overlap is not a CIGAR, edge positions assume no overlap")
+		}
+		fromLen, toLen := lengths[link.from.id()], lengths[link.to.id()]
+		from, fromOrient := link.GetFrom()
+		to, toOrient := link.GetTo()
+		var beg1, end1, beg2, end2 int
+		if fromOrient == "+" {
+			beg1, end1 = fromLen-refLen, fromLen
+		} else {
+			beg1, end1 = 0, refLen
+		}
+		if toOrient == "+" {
+			beg2, end2 = 0, queryLen
+		} else {
+			beg2, end2 = toLen-queryLen, toLen
+		}
+		optional := link.optional.clone()
+		edge := &Edge{
+			recordType: "E",
+			ID:         takeID(optional),
+			Sid1:       from,
+			sid1Orient: fromOrient,
+			Sid2:       to,
+			sid2Orient: toOrient,
+			beg1:       newPosition(beg1, fromLen),
+			end1:       newPosition(end1, fromLen),
+			beg2:       newPosition(beg2, toLen),
+			end2:       newPosition(end2, toLen),
+			alignment:  link.overlap,
+			optional:   optional,
+		}
+		edge.Add(newGFA)
+	}
+	// a containment covers the whole of the contained segment, starting at pos within the container
+	for _, containment := range gfa.containments {
+		containerLen, containedLen := lengths[containment.container.id()], lengths[containment.contained.id()]
+		container, containerOrient := containment.GetContainer()
+		contained, containedOrient := containment.GetContained()
+		refLen, _, err := cigarLengths(containment.overlap)
+		if err != nil {
+			refLen = containedLen
+		}
+		optional := containment.optional.clone()
+		edge := &Edge{
+			recordType: "E",
+			ID:         takeID(optional),
+			Sid1:       container,
+			sid1Orient: containerOrient,
+			Sid2:       contained,
+			sid2Orient: containedOrient,
+			beg1:       newPosition(containment.pos, containerLen),
+			end1:       newPosition(containment.pos+refLen, containerLen),
+			beg2:       newPosition(0, containedLen),
+			end2:       newPosition(containedLen, containedLen),
+			alignment:  containment.overlap,
+			optional:   optional,
+		}
+		edge.Add(newGFA)
+	}
+	// path overlaps are only kept if they can be recovered from the edges between the path segments
+	overlaps := linkOverlaps(gfa.links)
+	for _, path := range gfa.paths {
+		segNames := path.orientedNames()
+		group, err := NewOrderedGroup(path.name, segNames)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Can't convert path %v: %v", string(path.name), err)
+		}
+		group.optional = path.optional.clone()
+		if path.overlaps != "*" {
+			pathOverlaps := path.GetOverlaps()
+			recoverable := len(pathOverlaps) == len(segNames)-1
+			for i := 0; recoverable && i < len(pathOverlaps); i++ {
+				recoverable = overlaps[string(segNames[i])+string(segNames[i+1])] == pathOverlaps[i]
+			}
+			if !recoverable {
+				warn(path, "P", "path overlaps that differ from the link overlaps can't be stored in an ordered group")
+			}
+		}
+		group.Add(newGFA)
+	}
+	for _, jump := range gfa.jumps {
+		distance, ok := jump.GetDistance()
+		if !ok {
+			warn(jump, "J", "gaps require a distance")
+			continue
+		}
+		from, fromOrient := jump.GetFrom()
+		to, toOrient := jump.GetTo()
+		gap := &Gap{recordType: "G", ID: []byte("*"), Sid1: from, sid1Orient: fromOrient, Sid2: to, sid2Orient: toOrient, dist: distance, variance: "*", optional: jump.optional.clone()}
+		gap.Add(newGFA)
+	}
+	for _, walk := range gfa.walks {
+		warn(walk, "W", "walks can't be stored in GFA2")
+	}
+	return newGFA, warnings, nil
+}
+
+/*
+ConvertToGFA1 returns a new GFA1 instance containing the content of a GFA2 instance
+
+// edges are converted to links (dovetail overlaps) or containments, depending on their positions (the edge identifier becomes an ID tag)
+
+// ordered groups are converted to paths
+
+// gaps are converted to jumps
+
+// anything that can't be represented in GFA1 (fragments, unordered groups, internal alignments, segments with names that aren't allowed in GFA1
+and the records that reference them) is reported as a ConversionWarning
+*/
+func ConvertToGFA1(gfa *GFA) (*GFA, []*ConversionWarning, error) {
+	if gfa.GetVersion() != 2 {
+		return nil, nil, fmt.Errorf("Can only convert a GFA version 2 instance to GFA1")
+	}
+	if err := gfa.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("GFA validation failed, can't convert: %v", err)
+	}
+	warnings := []*ConversionWarning{}
+	warn := func(line Record, recordType, reason string) {
+		warnings = append(warnings, &ConversionWarning{RecordType: recordType, Record: line.PrintGFAline(), Reason: reason})
+	}
+	newGFA := NewGFA()
+	if err := newGFA.AddVersion(1); err != nil {
+		return nil, nil, err
+	}
+	newGFA.comments = append(newGFA.comments, gfa.comments...)
+	// segments that can't be stored in GFA1 (e.g. their name contains a + or -) are left out, with the records that reference them
+	skipped := make(map[string]bool)
+	for _, seg := range gfa.segments {
+		newSeg, err := NewSegment(seg.GetName(), seg.GetSequence())
+		if err != nil {
+			warn(seg, "S", fmt.Sprintf("segment can't be stored in GFA1: %v", err))
+			skipped[string(seg.GetName())] = true
+			continue
+		}
+		newSeg.Length = seg.Length
+		newSeg.optional = seg.optional.clone()
+		if err := newSeg.Add(newGFA); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, edge := range gfa.edges {
+		if skipped[string(edge.Sid1)] || skipped[string(edge.Sid2)] {
+			warn(edge, "E", "edge references a segment that can't be stored in GFA1")
+			continue
+		}
+		optional := edge.optional.clone()
+		if string(edge.ID) != "*" {
+			if optional == nil {
+				optional = new(OptionalFields)
+			}
+			if err := optional.SetString("ID", string(edge.ID)); err != nil {
+				return nil, nil, err
+			}
+		}
+		overlap := edge.alignment
+		if overlap != "*" {
+			if _, _, err := cigarLengths(overlap); err != nil {
+				warn(edge, "E", "trace alignment can't be stored in GFA1")
+				overlap = "*"
+			}
+		}
+		// determine if the aligned region of each oriented segment is at its start, its end or covers the whole segment
+		start1, stop1 := edge.beg1.Offset == 0, edge.end1.IsEnd
+		start2, stop2 := edge.beg2.Offset == 0, edge.end2.IsEnd
+		if edge.sid1Orient == "-" {
+			start1, stop1 = stop1, start1
+		}
+		if edge.sid2Orient == "-" {
+			start2, stop2 = stop2, start2
+		}
+		names := newNameTable()
+		sid1, sid2 := names.step(edge.Sid1, edge.sid1Orient), names.step(edge.Sid2, edge.sid2Orient)
+		var newLink *Link
+		switch {
+		case stop1 && start2:
+			newLink = &Link{recordType: "L", from: sid1, to: sid2, names: names, overlap: overlap, optional: optional}
+		case stop2 && start1:
+			newLink = &Link{recordType: "L", from: sid2, to: sid1, names: names, overlap: invertCIGAR(overlap), optional: optional}
+		case start2 && stop2:
+			containment := &Containment{recordType: "C", container: sid1, contained: sid2, names: names, pos: edge.beg1.Offset, overlap: overlap, optional: optional}
+			containment.Add(newGFA)
+			continue
+		case start1 && stop1:
+			containment := &Containment{recordType: "C", container: sid2, contained: sid1, names: names, pos: edge.beg2.Offset, overlap: invertCIGAR(overlap), optional: optional}
+			containment.Add(newGFA)
+			continue
+		default:
+			warn(edge, "E", "internal alignments can't be stored in GFA1")
+			continue
+		}
+		newLink.Add(newGFA)
+	}
+	for _, fragment := range gfa.fragments {
+		warn(fragment, "F", "fragments can't be stored in GFA1")
+	}
+	for _, gap := range gfa.gaps {
+		if skipped[string(gap.Sid1)] || skipped[string(gap.Sid2)] {
+			warn(gap, "G", "gap references a segment that can't be stored in GFA1")
+			continue
+		}
+		if string(gap.ID) != "*" {
+			warn(gap, "G", "gap identifier can't be stored in GFA1")
+		}
+		if gap.variance != "*" {
+			warn(gap, "G", "gap variance can't be stored in GFA1")
+		}
+		names := newNameTable()
+		jump := &Jump{recordType: "J", from: names.step(gap.Sid1, gap.sid1Orient), to: names.step(gap.Sid2, gap.sid2Orient), names: names, distance: strconv.Itoa(gap.dist), optional: gap.optional.clone()}
+		jump.Add(newGFA)
+	}
+	// paths are given the overlaps of the links between their segments
+	overlaps := linkOverlaps(newGFA.links)
+	segments := make(map[string]struct{})
+	for _, seg := range gfa.segments {
+		segments[seg.names.name(seg.id)] = struct{}{}
+	}
+	for _, group := range gfa.groups {
+		if !group.IsOrdered() {
+			warn(group, "U", "unordered groups can't be stored in GFA1")
+			continue
+		}
+		if string(group.ID) == "*" {
+			warn(group, "O", "paths require a name")
+			continue
+		}
+		if group.referencesAny(skipped) {
+			warn(group, "O", "group references a segment that can't be stored in GFA1")
+			continue
+		}
+		// edges referenced in the group are implied by the segments either side of them in a GFA1 path
+		segNames := [][]byte{}
+		for _, item := range group.Items {
+			if _, ok := segments[string(item[:len(item)-1])]; ok {
+				segNames = append(segNames, item)
+			} else if !gfa.isEdgeID(item[:len(item)-1]) {
+				warn(group, "O", "groups can't reference other groups in GFA1")
+			}
+		}
+		olaps := [][]byte{}
+		for i := 1; i < len(segNames); i++ {
+			overlap, ok := overlaps[string(segNames[i-1])+string(segNames[i])]
+			if !ok || overlap == "*" {
+				olaps = [][]byte{[]byte("*")}
+				break
+			}
+			olaps = append(olaps, []byte(overlap))
+		}
+		if len(olaps) == 0 {
+			olaps = [][]byte{[]byte("*")}
+		}
+		path, err := NewPath(group.ID, segNames, olaps)
+		if err != nil {
+			return nil, nil, err
+		}
+		path.optional = group.optional.clone()
+		path.Add(newGFA)
+	}
+	return newGFA, warnings, nil
+}
+
+// linkOverlaps returns the overlap between each pair of linked oriented segments (e.g. 1+2-), in both directions of traversal
+func linkOverlaps(links []*Link) map[string]string {
+	overlaps := make(map[string]string)
+	for _, link := range links {
+		from, to := link.names.handle(link.from), link.names.handle(link.to)
+		overlaps[from.String()+to.String()] = link.overlap
+		overlaps[to.Flip().String()+from.Flip().String()] = invertCIGAR(reverseCIGAR(link.overlap))
+	}
+	return overlaps
+}
+
+// takeID removes the ID tag from a set of optional fields, returning its value (or * if there is no ID tag)
+func takeID(oFs *OptionalFields) []byte {
+	id, err := oFs.GetString("ID")
+	if err != nil {
+		return []byte("*")
+	}
+	oFs.Remove("ID")
+	return []byte(id)
+}
+
+// referencesAny checks if any of the items of a group are for one of the named records (the orientation of an ordered group item is ignored)
+func (group *Group) referencesAny(names map[string]bool) bool {
+	for _, item := range group.Items {
+		if group.recordType == "O" {
+			item = item[:len(item)-1]
+		}
+		if names[string(item)] {
+			return true
+		}
+	}
+	return false
+}
+
+// isEdgeID checks if an identifier belongs to an edge in the GFA instance
+func (gfa *GFA) isEdgeID(id []byte) bool {
+	for _, edge := range gfa.edges {
+		if bytes.Equal(edge.ID, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// newPosition returns a GFA2 Position, marked as the segment end if the offset is the segment length
+func newPosition(offset, segLength int) Position {
+	return Position{Offset: offset, IsEnd: offset == segLength}
+}
@@ -0,0 +1,185 @@
+package gfa
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+var testGFA1 = strings.Join([]string{
+	"H\tVN:Z:1",
+	"S\t1\tCGATGCAA\tLN:i:8",
+	"S\t2\tTGCAAAGTAC\tLN:i:10",
+	"S\t3\tTGCAACGTATAGACTTGTCAC\tLN:i:21",
+	"S\t4\tGCAACG\tLN:i:6\tRC:i:12",
+	"L\t1\t+\t2\t+\t5M\tID:Z:e1",
+	"L\t3\t+\t2\t-\t2M1I2M",
+	"L\t2\t+\t1\t-\t0M",
+	"J\t1\t+\t3\t-\t100",
+	"C\t3\t+\t4\t+\t2\t6M",
+	"P\tp1\t1+,2+\t5M",
+}, "\n") + "\n"
+
+// readTestGFA reads a GFA instance from a string
+func readTestGFA(t *testing.T, input string) *GFA {
+	reader, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	myGFA := reader.CollectGFA()
+	for {
+		line, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := line.Add(myGFA); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return myGFA
+}
+
+// convert a GFA1 instance to GFA2 and back again
+func TestConvertToGFA2(t *testing.T) {
+	gfa1 := readTestGFA(t, testGFA1)
+	gfa2, warnings, err := ConvertToGFA2(gfa1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, warning := range warnings {
+		t.Log(warning)
+	}
+	if len(warnings) != 0 {
+		t.Fatal("lossless conversion to GFA2 gave warnings")
+	}
+	if err := gfa2.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	edges, _ := gfa2.GetEdges()
+	expected := []string{
+		"E\te1\t1+\t2+\t3\t8$\t0\t5\t5M",
+		"E\t*\t3+\t2-\t17\t21$\t5\t10$\t2M1I2M",
+		"E\t*\t2+\t1-\t10$\t10$\t8$\t8$\t0M",
+		"E\t*\t3+\t4+\t2\t8\t0\t6$\t6M",
+	}
+	for i, edge := range edges {
+		t.Log(edge.PrintGFAline())
+		if edge.PrintGFAline() != expected[i] {
+			t.Fatalf("edge not converted correctly, expected %v", expected[i])
+		}
+	}
+	groups, _ := gfa2.GetGroups()
+	if len(groups) != 1 || groups[0].PrintGFAline() != "O\tp1\t1+ 2+" {
+		t.Fatal("path not converted to an ordered group")
+	}
+	gaps, _ := gfa2.GetGaps()
+	if len(gaps) != 1 || gaps[0].PrintGFAline() != "G\t*\t1+\t3-\t100\t*" {
+		t.Fatal("jump not converted to a gap")
+	}
+	// and back again
+	roundTrip, warnings, err := ConvertToGFA1(gfa2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Fatal("lossless conversion to GFA1 gave warnings")
+	}
+	links, _ := roundTrip.GetLinks()
+	originalLinks, _ := gfa1.GetLinks()
+	for i, link := range links {
+		if link.PrintGFAline() != originalLinks[i].PrintGFAline() {
+			t.Fatalf("link did not survive round trip: %v", link.PrintGFAline())
+		}
+	}
+	segments, _ := roundTrip.GetSegments()
+	originalSegments, _ := gfa1.GetSegments()
+	for i, seg := range segments {
+		if seg.PrintGFAline() != originalSegments[i].PrintGFAline() {
+			t.Fatalf("segment did not survive round trip: %v", seg.PrintGFAline())
+		}
+	}
+	containments, _ := roundTrip.GetContainments()
+	if len(containments) != 1 || containments[0].PrintGFAline() != "C\t3\t+\t4\t+\t2\t6M" {
+		t.Fatal("containment did not survive round trip")
+	}
+	paths, _ := roundTrip.GetPaths()
+	if len(paths) != 1 || paths[0].PrintGFAline() != "P\tp1\t1+,2+\t5M" {
+		t.Fatal("path did not survive round trip")
+	}
+	jumps, _ := roundTrip.GetJumps()
+	if len(jumps) != 1 || jumps[0].PrintGFAline() != "J\t1\t+\t3\t-\t100" {
+		t.Fatal("jump did not survive round trip")
+	}
+}
+
+// convert a GFA2 instance to GFA1, reporting the records that can't be represented
+func TestConvertToGFA1(t *testing.T) {
+	gfa2 := readTestGFA(t, testGFA2)
+	gfa1, warnings, err := ConvertToGFA1(gfa2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gfa1.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, warning := range warnings {
+		t.Log(warning)
+	}
+	// the fragment, the gap id and the unordered group
+	if len(warnings) != 3 {
+		t.Fatalf("expected 3 conversion warnings, got %d", len(warnings))
+	}
+	links, _ := gfa1.GetLinks()
+	if len(links) != 2 {
+		t.Fatalf("expected 2 links, got %d", len(links))
+	}
+	if links[0].PrintGFAline() != "L\t1\t+\t2\t+\t5M\tID:Z:e1" {
+		t.Fatalf("edge not converted to link: %v", links[0].PrintGFAline())
+	}
+	paths, _ := gfa1.GetPaths()
+	if len(paths) != 1 || paths[0].PrintGFAline() != "P\tp1\t1+,2+\t5M" {
+		t.Fatal("ordered group not converted to path")
+	}
+	t.Log("checking version mismatches give an error:")
+	if _, _, err := ConvertToGFA2(gfa2); err != nil {
+		t.Log(err)
+	} else {
+		t.Fatal("converted a GFA2 instance to GFA2")
+	}
+}
+
+// test that unknown overlaps are converted without a warning, and that segments that can't be stored in GFA1 are skipped with a warning
+func TestConvertWarnings(t *testing.T) {
+	gfa1 := readTestGFA(t, "H\tVN:Z:1\nS\t1\tACGT\nS\t2\tTT\nL\t1\t+\t2\t-\t*\nL\t2\t+\t1\t+\tnot-a-cigar\n")
+	_, warnings, err := ConvertToGFA2(gfa1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || warnings[0].RecordType != "L" || !strings.Contains(warnings[0].Record, "not-a-cigar") {
+		t.Fatalf("expected a single warning for the overlap that is not a CIGAR, got %v", warnings)
+	}
+	gfa2 := readTestGFA(t, "H\tVN:Z:2.0\nS\t1\t4\tACGT\nS\tx-1\t2\tTT\nS\t3\t2\tGG\nE\t*\t1+\tx-1+\t4$\t4$\t0\t0\t*\nE\t*\t1+\t3+\t4$\t4$\t0\t0\t*\nO\tp1\t1+ x-1+\n")
+	gfa1, warnings, err = ConvertToGFA1(gfa2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gfa1.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	types := []string{}
+	for _, warning := range warnings {
+		types = append(types, warning.RecordType)
+	}
+	if strings.Join(types, ",") != "S,E,O" {
+		t.Fatalf("unexpected conversion warnings: %v", warnings)
+	}
+	if segments, _ := gfa1.GetSegments(); len(segments) != 2 {
+		t.Fatalf("expected 2 segments, got %d", len(segments))
+	}
+	if links, _ := gfa1.GetLinks(); len(links) != 1 || links[0].PrintGFAline() != "L\t1\t+\t3\t+\t*" {
+		t.Fatalf("unexpected links: %v", links)
+	}
+}
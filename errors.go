@@ -0,0 +1,56 @@
+package gfa
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The errors that a Reader can report for a malformed line, held as the Err of a ParseError
+var (
+	ErrEmptyLine         = errors.New("empty line")
+	ErrUnknownRecordType = errors.New("unknown record type")
+	ErrTooFewFields      = errors.New("not enough fields")
+	ErrWrongVersion      = errors.New("record type not allowed for the GFA version")
+)
+
+// ParseError is returned by a Reader when a line can't be parsed
+type ParseError struct {
+	Line       int    // the line number, starting at 1
+	RecordType string // the record type of the line (empty for a blank line)
+	Field      int    // the tab separated field that caused the error, where 0 is the record type, or -1 if no one field is at fault
+	Err        error
+}
+
+// Error returns the error message, including the line number and field of the error
+func (e *ParseError) Error() string {
+	if e.Field < 0 {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d, field %d (%v record): %v", e.Line, e.Field, e.RecordType, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// A fieldError records which field of a GFA line (counting the record type as field 0) a constructor error refers to
+type fieldError struct {
+	field int
+	err   error
+}
+
+// Error returns the underlying error message
+func (e *fieldError) Error() string {
+	return e.err.Error()
+}
+
+// newFieldError attaches a field number to an error
+func newFieldError(field int, err error) error {
+	return &fieldError{field: field, err: err}
+}
+
+// fieldErrorf formats an error and attaches a field number to it
+func fieldErrorf(field int, format string, a ...interface{}) error {
+	return &fieldError{field: field, err: fmt.Errorf(format, a...)}
+}
@@ -5,7 +5,7 @@ For more information:
     GFA Format Specification
     https://github.com/GFA-spec/GFA-spec
 
-This package currently only conforms to GFA1 spec.
+This package supports both the GFA1 and GFA2 specs.
 */
 package gfa
 
@@ -18,20 +18,33 @@ import (
 
 // The GFA type holds all the information from a GFA formatted file
 type GFA struct {
-	header       *header
+	header       *Header
 	comments     [][]byte
-	segments     []*segment
-	links        []*link
-	containments []containment // TODO: not yet implemented
-	paths        []*path
-	segRecord    map[string]struct{} // prevents duplicate segment IDs being added
+	segments     []*Segment
+	links        []*Link
+	containments []*Containment
+	paths        []*Path
+	walks        []*Walk
+	jumps        []*Jump
+	edges        []*Edge
+	fragments    []*Fragment
+	gaps         []*Gap
+	groups       []*Group
+	names        *nameTable       // segment names, interned into dense integer IDs
+	nodes        []segNode        // segment ID to the segment and the records that refer to it
+	pathIndex    map[string]*Path // path name to the first path with that name
+	lossless     bool             // if set, the order of all lines is tracked so that they can be written back in the same order
+	order        []Record
+	raw          map[Record]*rawLine // the original bytes of lines read in lossless mode
+	trailer      []byte              // any blank lines found at the end of a file read in lossless mode
 }
 
 // NewGFA returns a new GFA instance
 func NewGFA() *GFA {
 	return &GFA{
-		header:    &header{recordType: "H"},
-		segRecord: make(map[string]struct{}),
+		header:    &Header{recordType: "H"},
+		names:     newNameTable(),
+		pathIndex: make(map[string]*Path),
 	}
 }
 
@@ -40,10 +53,10 @@ func (gfa *GFA) AddVersion(v int) error {
 	switch v {
 	case 0:
 		return fmt.Errorf("GFA instance already has a version number attached")
-	case 1:
+	case 1, 2:
 		gfa.header.vn = v
-	case 2:
-		return fmt.Errorf("GFA version 2 is currently unsupported...")
+		gfa.header.version = ""
+		gfa.trackVersion(v)
 	default:
 		return fmt.Errorf("GFA format must be either version 1 or version 2")
 	}
@@ -52,8 +65,7 @@ func (gfa *GFA) AddVersion(v int) error {
 
 // AddComment appends a comment to the comments held by the GFA instance
 func (gfa *GFA) AddComment(c []byte) {
-	comment := append([]byte("#\t"), c...)
-	gfa.comments = append(gfa.comments, comment)
+	newComment(append([]byte("\t"), c...)).Add(gfa)
 }
 
 /*
@@ -66,7 +78,7 @@ func (gfa *GFA) GetVersion() int {
 }
 
 // GetSegments returns a slice of all the segments held in the GFA instance
-func (gfa *GFA) GetSegments() ([]*segment, error) {
+func (gfa *GFA) GetSegments() ([]*Segment, error) {
 	if len(gfa.segments) == 0 {
 		return nil, fmt.Errorf("no segments currently held in GFA instance")
 	}
@@ -74,12 +86,61 @@ func (gfa *GFA) GetSegments() ([]*segment, error) {
 }
 
 // GetLinks returns a slice of all the links held in the GFA instance
-func (gfa *GFA) GetLinks() ([]*link, error) {
+func (gfa *GFA) GetLinks() ([]*Link, error) {
 	return gfa.links, nil
 }
 
+// GetContainments returns a slice of all the containments held in the GFA instance
+func (gfa *GFA) GetContainments() ([]*Containment, error) {
+	return gfa.containments, nil
+}
+
+// GetSegment returns the segment with the given name, ok is false if it is not in the GFA instance
+func (gfa *GFA) GetSegment(name []byte) (*Segment, bool) {
+	id, ok := gfa.lookup(name)
+	if !ok || gfa.segment(id) == nil {
+		return nil, false
+	}
+	return gfa.segment(id), true
+}
+
+// GetPath returns the path with the given name (the first one added if there is more than one), ok is false if it is not in the GFA instance
+func (gfa *GFA) GetPath(name []byte) (*Path, bool) {
+	path, ok := gfa.pathIndex[string(name)]
+	return path, ok
+}
+
+// LinksFrom returns the links that start from a segment in a given orientation (+/-), as written in the L lines
+func (gfa *GFA) LinksFrom(name []byte, orient string) []*Link {
+	id, ok := gfa.lookup(name)
+	bit, valid := orientBit(orient)
+	if !ok || !valid {
+		return nil
+	}
+	return gfa.nodes[id].linksFrom[bit]
+}
+
+// LinksTo returns the links that go to a segment in a given orientation (+/-), as written in the L lines
+func (gfa *GFA) LinksTo(name []byte, orient string) []*Link {
+	id, ok := gfa.lookup(name)
+	bit, valid := orientBit(orient)
+	if !ok || !valid {
+		return nil
+	}
+	return gfa.nodes[id].linksTo[bit]
+}
+
+// PathsThrough returns the paths that include a segment, in the order they were added
+func (gfa *GFA) PathsThrough(name []byte) []*Path {
+	id, ok := gfa.lookup(name)
+	if !ok {
+		return nil
+	}
+	return gfa.nodes[id].paths
+}
+
 // GetPaths returns a slice of all the paths held in the GFA instance
-func (gfa *GFA) GetPaths() ([]*path, error) {
+func (gfa *GFA) GetPaths() ([]*Path, error) {
 	if len(gfa.paths) == 0 {
 		return nil, fmt.Errorf("no paths currently held in GFA instance")
 	}
@@ -88,7 +149,7 @@ func (gfa *GFA) GetPaths() ([]*path, error) {
 
 // PrintHeader prints the GFA formatted header line
 func (gfa *GFA) PrintHeader() string {
-	return fmt.Sprintf("%v\tVN:Z:%v", gfa.header.recordType, gfa.header.vn)
+	return appendOptionalFields(fmt.Sprintf("%v\tVN:Z:%v", gfa.header.recordType, gfa.header.versionString()), gfa.header.optional)
 }
 
 // PrintComments prints a string of GFA formatted comment line(s)
@@ -97,11 +158,20 @@ func (gfa *GFA) PrintComments() string {
 }
 
 /*
-Validate performs several checks on the GFA instance TODO: add more checks
+Validate performs several checks on the GFA instance and returns the first error, ValidateReport gives a full list of problems
 
 // checks that it contains a version (1/2)
 
 // checks that is contains 1 or more segments
+
+// checks that only records belonging to the GFA version are present
+
+// checks that links, containments, jumps, paths and walks only reference segments held in the GFA instance
+
+// checks that GFA2 edges, gaps, fragments and groups reference known identifiers
+
+// Validate only checks the structure of the GFA instance (it is run before every write), ValidateReport also checks the content of the
+records (sequences, LN and SH tags, CIGAR overlaps, path overlap counts and duplicate links)
 */
 func (gfa *GFA) Validate() error {
 	if gfa.GetVersion() == 0 {
@@ -113,13 +183,65 @@ func (gfa *GFA) Validate() error {
 	if len(gfa.segments) == 0 {
 		return fmt.Errorf("GFA instance contains no segments")
 	}
+	for _, seg := range gfa.segments {
+		if seg.version != 0 && seg.version != gfa.GetVersion() {
+			return fmt.Errorf("Segment %v is not formatted for GFA version %d", string(seg.GetName()), gfa.GetVersion())
+		}
+	}
+	if gfa.GetVersion() == 2 {
+		if len(gfa.links) != 0 || len(gfa.containments) != 0 || len(gfa.paths) != 0 || len(gfa.walks) != 0 || len(gfa.jumps) != 0 {
+			return fmt.Errorf("GFA version 2 can't contain link, containment, path, walk or jump records")
+		}
+		return gfa.validateGFA2()
+	}
+	if len(gfa.edges) != 0 || len(gfa.fragments) != 0 || len(gfa.gaps) != 0 || len(gfa.groups) != 0 {
+		return fmt.Errorf("GFA version 1 can't contain edge, fragment, gap or group records")
+	}
+	for _, l := range gfa.links {
+		if gfa.segment(l.from.id()) == nil {
+			return fmt.Errorf("Link references an unknown segment: %v", l.names.name(l.from.id()))
+		}
+		if gfa.segment(l.to.id()) == nil {
+			return fmt.Errorf("Link references an unknown segment: %v", l.names.name(l.to.id()))
+		}
+	}
+	for _, c := range gfa.containments {
+		if gfa.segment(c.container.id()) == nil {
+			return fmt.Errorf("Containment references an unknown container segment: %v", c.names.name(c.container.id()))
+		}
+		if gfa.segment(c.contained.id()) == nil {
+			return fmt.Errorf("Containment references an unknown contained segment: %v", c.names.name(c.contained.id()))
+		}
+	}
+	for _, j := range gfa.jumps {
+		if gfa.segment(j.from.id()) == nil {
+			return fmt.Errorf("Jump references an unknown segment: %v", j.names.name(j.from.id()))
+		}
+		if gfa.segment(j.to.id()) == nil {
+			return fmt.Errorf("Jump references an unknown segment: %v", j.names.name(j.to.id()))
+		}
+	}
+	for _, p := range gfa.paths {
+		for _, s := range p.steps {
+			if gfa.segment(s.id()) == nil {
+				return fmt.Errorf("Path %v references an unknown segment: %v", string(p.name), p.names.name(s.id()))
+			}
+		}
+	}
+	for _, w := range gfa.walks {
+		for _, s := range w.steps {
+			if gfa.segment(s.id()) == nil {
+				return fmt.Errorf("Walk references an unknown segment: %v", w.names.name(s.id()))
+			}
+		}
+	}
 	return nil
 }
 
 // MarshalHeader prepares the header/comment lines for a writer
 func (gfa *GFA) MarshalHeader() []byte {
 	var buf bytes.Buffer
-	fmt.Fprintf(&buf, "%v\tVN:Z:%v\n", gfa.header.recordType, gfa.header.vn)
+	fmt.Fprintf(&buf, "%v\n", gfa.PrintHeader())
 	if len(gfa.comments) != 0 {
 		fmt.Fprintf(&buf, "%s", bytes.Join(gfa.comments, []byte("\n")))
 		buf.WriteByte('\n')
@@ -127,274 +249,807 @@ func (gfa *GFA) MarshalHeader() []byte {
 	return buf.Bytes()
 }
 
-// WriteGFAContent will dump the content of a GFA instance to file
+// WriteGFAContent will dump the content of a GFA instance to file (a GFA read in lossless mode is written in its original line order)
 func (gfa *GFA) WriteGFAContent(w *GFAwriter) error {
 	if err := gfa.Validate(); err != nil {
 		return fmt.Errorf("GFA validation failed, can't write GFA content: %v", err)
 	}
-	for _, seg := range gfa.segments {
+	if gfa.lossless {
+		return gfa.writeLossless(w)
+	}
+	segments, links := gfa.segments, gfa.links
+	if w.topological {
+		segments, links = gfa.topologicalRecords()
+	}
+	for _, seg := range segments {
 		err := w.Write(seg)
 		if err != nil {
 			return fmt.Errorf("Can't write GFA content: %v", err)
 		}
 	}
-	for _, link := range gfa.links {
+	for _, link := range links {
 		err := w.Write(link)
 		if err != nil {
 			return fmt.Errorf("Can't write GFA content: %v", err)
 		}
 	}
+	for _, jump := range gfa.jumps {
+		err := w.Write(jump)
+		if err != nil {
+			return fmt.Errorf("Can't write GFA content: %v", err)
+		}
+	}
+	for _, containment := range gfa.containments {
+		err := w.Write(containment)
+		if err != nil {
+			return fmt.Errorf("Can't write GFA content: %v", err)
+		}
+	}
 	for _, path := range gfa.paths {
 		err := w.Write(path)
 		if err != nil {
 			return fmt.Errorf("Can't write GFA content: %v", err)
 		}
 	}
+	for _, walk := range gfa.walks {
+		err := w.Write(walk)
+		if err != nil {
+			return fmt.Errorf("Can't write GFA content: %v", err)
+		}
+	}
+	for _, fragment := range gfa.fragments {
+		err := w.Write(fragment)
+		if err != nil {
+			return fmt.Errorf("Can't write GFA content: %v", err)
+		}
+	}
+	for _, edge := range gfa.edges {
+		err := w.Write(edge)
+		if err != nil {
+			return fmt.Errorf("Can't write GFA content: %v", err)
+		}
+	}
+	for _, gap := range gfa.gaps {
+		err := w.Write(gap)
+		if err != nil {
+			return fmt.Errorf("Can't write GFA content: %v", err)
+		}
+	}
+	for _, group := range gfa.groups {
+		err := w.Write(group)
+		if err != nil {
+			return fmt.Errorf("Can't write GFA content: %v", err)
+		}
+	}
 	return nil
 }
 
-// PrintSequence will return the sequence encoded by a specified pathName
+/*
+PrintSequence will return the sequence encoded by a specified pathName
+
+// segments traversed in reverse (-) are reverse complemented, including any IUPAC ambiguity codes
+
+// the bases of each segment that are overlapped by the previous segment are only spelled once
+
+// the path overlaps are used if there is one between each pair of steps, otherwise (or for an overlap of *) the overlaps of the links between the steps are used
+*/
 func (gfa *GFA) PrintSequence(pathName []byte) ([]byte, error) {
-	sequence := []byte{}
+	return gfa.PrintSequenceRange(pathName, 0, -1)
+}
+
+// PrintSequenceRange will return part of the sequence encoded by a specified pathName, from the 0-based start up to (but not including) end,
+// an end of -1 spells to the end of the path (only the segments that overlap the range are decoded)
+func (gfa *GFA) PrintSequenceRange(pathName []byte, start, end int) ([]byte, error) {
 	if err := gfa.Validate(); err != nil {
 		return nil, err
 	}
 	// get the specified path from the graph
-	for _, path := range gfa.paths {
-		if string(path.PathName) == string(pathName) {
-			// read the graph segments into a map
-			segMap := make(map[string][]byte)
-			for _, seg := range gfa.segments {
-				segMap[string(seg.Name)] = seg.Sequence
-			}
-			// build up the sequence using the path and segMap
-			for _, name := range path.SegNames {
-				// remove the plus from the seg name, lookup seg in map, append seq
-				sequence = append(sequence, segMap[string(bytes.TrimSuffix(name, []byte("+")))]...)
+	path, ok := gfa.pathIndex[string(pathName)]
+	if !ok {
+		return nil, fmt.Errorf("specified pathName not found in GFA")
+	}
+	return gfa.spell(path.names, path.steps, path.stepOverlaps(), start, end)
+}
+
+// stepOverlaps returns the overlaps of a path if there is one between each pair of steps, otherwise nil
+func (path *Path) stepOverlaps() []string {
+	overlaps := path.GetOverlaps()
+	if len(overlaps) != len(path.steps)-1 {
+		return nil
+	}
+	return overlaps
+}
+
+// stepSkips returns the number of bases at the start of each step that are overlapped by the step before it, using the overlap
+// between each pair of steps (or the link overlaps if overlaps is nil)
+func (gfa *GFA) stepSkips(names *nameTable, steps []step, overlaps []string) ([]int, error) {
+	skips := make([]int, len(steps))
+	for i, s := range steps {
+		seg := gfa.segment(s.id())
+		if seg == nil {
+			return nil, fmt.Errorf("Segment not found in GFA instance: %v", names.name(s.id()))
+		}
+		if i == 0 {
+			continue
+		}
+		overlap := "*"
+		if overlaps != nil {
+			overlap = overlaps[i-1]
+		}
+		skip, err := gfa.overlapLength(overlap, steps[i-1], s)
+		if err != nil {
+			return nil, err
+		}
+		if skip > seg.Length {
+			return nil, fmt.Errorf("Overlap of %d bases is longer than segment %v", skip, names.name(s.id()))
+		}
+		skips[i] = skip
+	}
+	return skips, nil
+}
+
+// spell builds up the sequence encoded by a list of steps, from start up to end (or the end of the sequence if end is -1), using the
+// overlap between each pair of steps (or the link overlaps if overlaps is nil)
+func (gfa *GFA) spell(names *nameTable, steps []step, overlaps []string, start, end int) ([]byte, error) {
+	if start < 0 || (end != -1 && end < start) {
+		return nil, fmt.Errorf("Sequence range %d-%d is not valid", start, end)
+	}
+	skips, err := gfa.stepSkips(names, steps, overlaps)
+	if err != nil {
+		return nil, err
+	}
+	sequence := []byte{}
+	// pos is the position in the spelled sequence of the first base of the current step that is not overlapped
+	pos := 0
+	for i, s := range steps {
+		seg := gfa.segment(s.id())
+		if !seg.HasSequence() {
+			return nil, fmt.Errorf("Segment %v has no sequence (*), it can be filled in with ResolveSequences", names.name(s.id()))
+		}
+		skip := skips[i]
+		// lo and hi are the bases of the segment, in the orientation of the step, that fall in the range
+		lo, hi := skip, seg.seq.length
+		if start > pos {
+			lo += start - pos
+		}
+		if end != -1 && end < pos+hi-skip {
+			hi = skip + end - pos
+		}
+		pos += seg.seq.length - skip
+		if lo >= hi {
+			if end != -1 && pos >= end {
+				break
 			}
-			break
+			continue
+		}
+		// decode the bases straight onto the end of the sequence
+		if s.reverse() {
+			offset := len(sequence)
+			sequence = seg.seq.appendRange(sequence, seg.seq.length-hi, seg.seq.length-lo)
+			reverseComplementInPlace(sequence[offset:])
+		} else {
+			sequence = seg.seq.appendRange(sequence, lo, hi)
 		}
 	}
-	// if the specified pathName wasn't found, return error
-	if len(sequence) == 0 {
-		return nil, fmt.Errorf("specified pathName not found in GFA")
+	if end > pos || start > pos {
+		return nil, fmt.Errorf("Sequence range %d-%d is beyond the end of the sequence (length %d)", start, end, pos)
 	}
 	return sequence, nil
 }
 
-// A header contains a type field (required) and a GFA version number field (optional)
-type header struct {
+// A Header contains a type field (required) and a GFA version number field (optional), plus any other optional fields
+type Header struct {
 	recordType string
 	vn         int
+	version    string // the VN tag value as it was read (e.g. 1.1)
+	optional   *OptionalFields
 }
 
-// An interface for the non-comment/header GFA lines
-type gfaLine interface {
-	AddOptionalFields(*optionalFields)
+// newHeader is a constructor for a header line, the VN tag is held separately from any other optional fields
+func newHeader(optional ...[]byte) (*Header, error) {
+	header := &Header{recordType: "H"}
+	oFs := new(OptionalFields)
+	for i, field := range optional {
+		if err := oFs.add(field); err != nil {
+			return nil, newFieldError(i+1, err)
+		}
+		if oFs.fields[i].tag != "VN" {
+			continue
+		}
+		version, err := oFs.GetString("VN")
+		if err != nil {
+			return nil, newFieldError(i+1, err)
+		}
+		switch version {
+		case "1", "1.0", "1.1", "1.2":
+			header.vn = 1
+		case "2", "2.0":
+			header.vn = 2
+		default:
+			return nil, fieldErrorf(i+1, "GFA version not recognised: %v", version)
+		}
+		header.version = version
+	}
+	oFs.Remove("VN")
+	if len(oFs.fields) != 0 {
+		header.optional = oFs
+	}
+	return header, nil
+}
+
+// versionString returns the VN tag value for the header (GFA2 files use 2.0)
+func (header *Header) versionString() string {
+	if header.version != "" {
+		return header.version
+	}
+	if header.vn == 2 {
+		return "2.0"
+	}
+	return strconv.Itoa(header.vn)
+}
+
+// GetVersion returns the GFA version given by a header (0 if the header has no VN tag)
+func (header *Header) GetVersion() int {
+	return header.vn
+}
+
+// GetRecordType returns the record type of a header (H)
+func (header *Header) GetRecordType() string {
+	return header.recordType
+}
+
+// GetOptionalFields returns the optional fields of a header, not including the VN tag (an empty set is added if there are none, so tags can be set on it)
+func (header *Header) GetOptionalFields() *OptionalFields {
+	if header.optional == nil {
+		header.optional = new(OptionalFields)
+	}
+	return header.optional
+}
+
+// AddOptionalFields adds a set of optional fields to a header
+func (header *Header) AddOptionalFields(oFs *OptionalFields) {
+	header.optional = oFs
+}
+
+// PrintGFAline prints a GFA formatted header line
+func (header *Header) PrintGFAline() string {
+	line := header.recordType
+	if header.vn != 0 {
+		line = fmt.Sprintf("%v\tVN:Z:%v", line, header.versionString())
+	}
+	return appendOptionalFields(line, header.optional)
+}
+
+// Add merges a header line into the header of a specified GFA instance
+func (header *Header) Add(gfa *GFA) error {
+	if header.vn != 0 {
+		if gfa.header.vn != 0 && gfa.header.vn != header.vn {
+			return fmt.Errorf("Header version (%d) conflicts with GFA instance version (%d)", header.vn, gfa.header.vn)
+		}
+		gfa.header.vn, gfa.header.version = header.vn, header.version
+	}
+	if header.optional != nil {
+		if gfa.header.optional == nil {
+			gfa.header.optional = new(OptionalFields)
+		}
+		for _, oF := range header.optional.fields {
+			if err := gfa.header.optional.set(oF.tag, oF.typ, oF.value); err != nil {
+				return err
+			}
+		}
+	}
+	gfa.track(header)
+	return nil
+}
+
+// A Comment is a GFA line beginning with #
+type Comment struct {
+	recordType string
+	text       []byte // the comment, without the leading #
+}
+
+// newComment is a comment constructor
+func newComment(text []byte) *Comment {
+	return &Comment{recordType: "#", text: text}
+}
+
+// AddOptionalFields is a no-op, comments do not have optional fields
+func (comment *Comment) AddOptionalFields(oFs *OptionalFields) {}
+
+// GetOptionalFields returns nil, comments do not have optional fields
+func (comment *Comment) GetOptionalFields() *OptionalFields {
+	return nil
+}
+
+// GetRecordType returns the record type of a comment (#)
+func (comment *Comment) GetRecordType() string {
+	return comment.recordType
+}
+
+// PrintGFAline prints a GFA formatted comment line
+func (comment *Comment) PrintGFAline() string {
+	return comment.recordType + string(comment.text)
+}
+
+// GetText returns the text of a comment, without the leading #
+func (comment *Comment) GetText() []byte {
+	return comment.text
+}
+
+// Add appends a comment to a specified GFA instance
+func (comment *Comment) Add(gfa *GFA) error {
+	gfa.comments = append(gfa.comments, []byte(comment.PrintGFAline()))
+	gfa.track(comment)
+	return nil
+}
+
+// Record is the interface satisfied by every type of GFA line (headers, comments and records)
+type Record interface {
+	GetRecordType() string
+	GetOptionalFields() *OptionalFields
+	AddOptionalFields(*OptionalFields)
 	PrintGFAline() string
 	Add(*GFA) error
 }
 
-// A segment contains a type field, name and sequence (all required), plus optional fields (length, ...)
-type segment struct {
+// A Segment contains a type field, name and sequence (all required), plus optional fields (length, ...)
+type Segment struct {
 	recordType string
-	Name       []byte
-	Sequence   []byte // this is technically not required by the spec but I have set it as required here
-	Length     int    // this is technically an optional field but is added automatically when a sequence is supplied
-	optional   *optionalFields
+	id         segID
+	names      *nameTable     // the table the segment name is interned in
+	seq        packedSequence // this is technically not required by the spec but I have set it as required here
+	Length     int            // this is technically an optional field but is added automatically when a sequence is supplied (or taken from the LN tag if the sequence is *)
+	version    int            // the GFA version the segment is formatted for (GFA2 segments carry an explicit length field)
+	optional   *OptionalFields
 }
 
 // NewSegment is a segment constructor
-func NewSegment(n, seq []byte) (*segment, error) {
+func NewSegment(n, seq []byte) (*Segment, error) {
+	return newSegment(newNameTable(), n, seq)
+}
+
+// newSegment creates a segment with its name interned in the given name table
+func newSegment(names *nameTable, n, seq []byte) (*Segment, error) {
 	if bytes.ContainsAny(n, "+-*= ") {
-		return nil, fmt.Errorf("Segment name can't contain +/-/*/= or whitespace")
+		return nil, fieldErrorf(1, "Segment name can't contain +/-/*/= or whitespace")
 	}
-	if len(seq) == 0 {
-		return nil, fmt.Errorf("Segment must have a sequence")
+	if err := checkSequence(seq, 1); err != nil {
+		return nil, newFieldError(2, err)
 	}
-	return &segment{
+	seg := &Segment{
 		recordType: "S",
-		Name:       n,
-		Sequence:   seq,
+		id:         names.intern(string(n)),
+		names:      names,
+		seq:        packSequence(seq),
 		Length:     len(seq),
+		version:    1,
+	}
+	// the length of a segment without a sequence is not known until an LN tag is added
+	if seg.seq.isPlaceholder() {
+		seg.Length = 0
+	}
+	return seg, nil
+}
+
+// NewGFA2Segment is a segment constructor for GFA2, where the segment length is given explicitly
+func NewGFA2Segment(n, length, seq []byte) (*Segment, error) {
+	return newGFA2Segment(newNameTable(), n, length, seq)
+}
+
+// newGFA2Segment creates a GFA2 segment with its name interned in the given name table
+func newGFA2Segment(names *nameTable, n, length, seq []byte) (*Segment, error) {
+	if err := checkGFA2ID(n); err != nil {
+		return nil, newFieldError(1, err)
+	}
+	l, err := strconv.Atoi(string(length))
+	if err != nil || l < 0 {
+		return nil, fieldErrorf(2, "Segment length must be a non-negative integer: %v", string(length))
+	}
+	if err := checkSequence(seq, 2); err != nil {
+		return nil, newFieldError(3, err)
+	}
+	return &Segment{
+		recordType: "S",
+		id:         names.intern(string(n)),
+		names:      names,
+		seq:        packSequence(seq),
+		Length:     l,
+		version:    2,
 	}, nil
 }
 
-// AddOptionalFields adds a set of optional fields to a segment
-func (seg *segment) AddOptionalFields(oFs *optionalFields) {
+// AddOptionalFields adds a set of optional fields to a segment, a GFA1 segment without a sequence takes its length from the LN tag
+func (seg *Segment) AddOptionalFields(oFs *OptionalFields) {
 	seg.optional = oFs
+	if seg.version == 1 && seg.seq.isPlaceholder() {
+		if length, err := oFs.GetInt("LN"); err == nil {
+			seg.Length = length
+		}
+	}
+}
+
+// GetOptionalFields returns the optional fields of a segment (an empty set is added if there are none, so tags can be set on it)
+func (seg *Segment) GetOptionalFields() *OptionalFields {
+	if seg.optional == nil {
+		seg.optional = new(OptionalFields)
+	}
+	return seg.optional
+}
+
+// GetRecordType returns the record type of a segment (S)
+func (seg *Segment) GetRecordType() string {
+	return seg.recordType
+}
+
+// GetName returns the name of a segment
+func (seg *Segment) GetName() []byte {
+	return []byte(seg.names.name(seg.id))
+}
+
+// GetSequence returns the sequence of a segment (* if the sequence is not stored), decoded from its packed form
+func (seg *Segment) GetSequence() []byte {
+	return seg.seq.appendRange(nil, 0, seg.seq.length)
+}
+
+// GetSequenceRange returns part of the sequence of a segment, from the 0-based start up to (but not including) end,
+// only the requested bases are decoded
+func (seg *Segment) GetSequenceRange(start, end int) ([]byte, error) {
+	if !seg.HasSequence() {
+		return nil, fmt.Errorf("Segment %v has no sequence (*)", seg.names.name(seg.id))
+	}
+	if start < 0 || end > seg.seq.length || start > end {
+		return nil, fmt.Errorf("Sequence range %d-%d is outside segment %v (length %d)", start, end, seg.names.name(seg.id), seg.seq.length)
+	}
+	return seg.seq.appendRange(nil, start, end), nil
+}
+
+// HasSequence returns false if the sequence of a segment is * (not stored)
+func (seg *Segment) HasSequence() bool {
+	return !seg.seq.isPlaceholder()
+}
+
+// GetLength returns the length of a segment sequence (0 if the segment has no sequence and no LN tag)
+func (seg *Segment) GetLength() int {
+	return seg.Length
 }
 
 // GetKmerCount returns the k-mer count of a segment
-func (seg *segment) GetKmerCount() (int, error) {
-	if seg.optional != nil {
-		if seg.optional.kmerCount != "" {
-			return strconv.Atoi(seg.optional.kmerCount)
-		}
+func (seg *Segment) GetKmerCount() (int, error) {
+	if seg.optional.Has("KC") {
+		return seg.optional.GetInt("KC")
 	}
 	return 0, nil
 }
 
 // PrintGFAline prints a GFA formatted segment line
-func (seg *segment) PrintGFAline() string {
-	if seg.optional != nil {
-		return fmt.Sprintf("%v\t%v\t%v\tLN:i:%v\t%v", seg.recordType, string(seg.Name), string(seg.Sequence), seg.Length, seg.optional.printString)
+func (seg *Segment) PrintGFAline() string {
+	if seg.version == 2 {
+		return appendOptionalFields(fmt.Sprintf("%v\t%v\t%v\t%v", seg.recordType, seg.names.name(seg.id), seg.Length, string(seg.GetSequence())), seg.optional)
 	}
-	return fmt.Sprintf("%v\t%v\t%v\tLN:i:%v", seg.recordType, string(seg.Name), string(seg.Sequence), seg.Length)
+	line := fmt.Sprintf("%v\t%v\t%v", seg.recordType, seg.names.name(seg.id), string(seg.GetSequence()))
+	// the length is added automatically, unless the segment already has a length tag or the length is not known
+	if !seg.optional.Has("LN") && (seg.HasSequence() || seg.Length > 0) {
+		line = fmt.Sprintf("%v\tLN:i:%v", line, seg.Length)
+	}
+	return appendOptionalFields(line, seg.optional)
 }
 
 // Add checks that a segment is not already in a specified GFA isntance, then adds it
-func (seg *segment) Add(gfa *GFA) error {
-	if _, ok := gfa.segRecord[string(seg.Name)]; ok {
-		return fmt.Errorf("Duplicate segment name already present in GFA instance: %v", string(seg.Name))
+func (seg *Segment) Add(gfa *GFA) error {
+	name := seg.names.name(seg.id)
+	id := gfa.intern(name)
+	if gfa.segment(id) != nil {
+		return fmt.Errorf("Duplicate segment name already present in GFA instance: %v", name)
 	}
+	seg.id, seg.names = id, gfa.names
 	gfa.segments = append(gfa.segments, seg)
-	gfa.segRecord[string(seg.Name)] = struct{}{}
+	gfa.nodes[id].seg = seg
+	gfa.track(seg)
 	return nil
 }
 
-// A link connects oriented segments
-type link struct {
+// A Link connects oriented segments
+type Link struct {
 	recordType string
-	From       []byte
-	fromOrient string
-	To         []byte
-	toOrient   string
+	from       step
+	to         step
+	names      *nameTable // the table the segment names are interned in, which becomes the table of the GFA instance when the link is added
 	overlap    string
-	optional   *optionalFields
+	optional   *OptionalFields
 }
 
 // NewLink is a link constructor
-func NewLink(from, fOrient, to, tOrient, overlap []byte) (*link, error) {
+func NewLink(from, fOrient, to, tOrient, overlap []byte) (*Link, error) {
+	return newLink(newNameTable(), from, fOrient, to, tOrient, overlap)
+}
+
+// newLink creates a link with its segment names interned in the given name table
+func newLink(names *nameTable, from, fOrient, to, tOrient, overlap []byte) (*Link, error) {
 	if bytes.ContainsAny(from, "+-*= ") {
-		return nil, fmt.Errorf("Segment name can't contain +/-/*/= or whitespace")
+		return nil, fieldErrorf(1, "Segment name can't contain +/-/*/= or whitespace")
 	}
 	if bytes.ContainsAny(to, "+-*= ") {
-		return nil, fmt.Errorf("Segment name can't contain +/-/*/= or whitespace")
+		return nil, fieldErrorf(3, "Segment name can't contain +/-/*/= or whitespace")
 	}
-	link := new(link)
-	link.From = from
-	link.To = to
+	link := new(Link)
+	link.names = names
 	link.recordType = "L"
 	fori, tori := string(fOrient), string(tOrient)
 	if (fori == "+") || (fori == "-") {
-		link.fromOrient = fori
+		link.from = link.names.step(from, fori)
 	} else {
-		return nil, fmt.Errorf("From orientation field must be either + or -")
+		return nil, fieldErrorf(2, "From orientation field must be either + or -")
 	}
 	if (tori == "+") || (tori == "-") {
-		link.toOrient = tori
+		link.to = link.names.step(to, tori)
 	} else {
-		return nil, fmt.Errorf("To orientation field must be either + or -")
+		return nil, fieldErrorf(4, "To orientation field must be either + or -")
 	}
 	link.overlap = string(overlap)
 	return link, nil
 }
 
 // AddOptionalFields adds a set of optional fields to a link
-func (link *link) AddOptionalFields(oFs *optionalFields) {
+func (link *Link) AddOptionalFields(oFs *OptionalFields) {
 	link.optional = oFs
 }
 
-// PrintGFAline prints a GFA formatted link line
-func (link *link) PrintGFAline() string {
-	if link.optional != nil {
-		return fmt.Sprintf("%v\t%v\t%v\t%v\t%v\t%v\t%v", link.recordType, string(link.From), link.fromOrient, string(link.To), link.toOrient, link.overlap, link.optional.printString)
+// GetOptionalFields returns the optional fields of a link (an empty set is added if there are none, so tags can be set on it)
+func (link *Link) GetOptionalFields() *OptionalFields {
+	if link.optional == nil {
+		link.optional = new(OptionalFields)
 	}
-	return fmt.Sprintf("%v\t%v\t%v\t%v\t%v\t%v", link.recordType, string(link.From), link.fromOrient, string(link.To), link.toOrient, link.overlap)
+	return link.optional
+}
+
+// GetRecordType returns the record type of a link (L)
+func (link *Link) GetRecordType() string {
+	return link.recordType
+}
+
+// GetFrom returns the name and orientation (+/-) of the segment a link starts from
+func (link *Link) GetFrom() ([]byte, string) {
+	return []byte(link.names.name(link.from.id())), link.from.orient()
+}
+
+// GetTo returns the name and orientation (+/-) of the segment a link goes to
+func (link *Link) GetTo() ([]byte, string) {
+	return []byte(link.names.name(link.to.id())), link.to.orient()
+}
+
+// GetOverlap returns the overlap of a link, as a CIGAR string (or * if not given)
+func (link *Link) GetOverlap() string {
+	return link.overlap
+}
+
+// PrintGFAline prints a GFA formatted link line
+func (link *Link) PrintGFAline() string {
+	return appendOptionalFields(fmt.Sprintf("%v\t%v\t%v\t%v\t%v\t%v", link.recordType, link.names.name(link.from.id()), link.from.orient(), link.names.name(link.to.id()), link.to.orient(), link.overlap), link.optional)
 }
 
 // Add appends a link to a specified GFA instance
-func (link *link) Add(gfa *GFA) error {
+func (link *Link) Add(gfa *GFA) error {
+	link.from, link.to, link.names = gfa.remap(link.names, link.from), gfa.remap(link.names, link.to), gfa.names
 	gfa.links = append(gfa.links, link)
+	from, to := &gfa.nodes[link.from.id()].linksFrom[link.from&1], &gfa.nodes[link.to.id()].linksTo[link.to&1]
+	*from = append(*from, link)
+	*to = append(*to, link)
+	gfa.track(link)
 	return nil
 }
 
-// containment
-type containment struct {
+// A Containment records that one segment is contained within another
+type Containment struct {
 	recordType string
+	container  step
+	contained  step
+	names      *nameTable
+	pos        int
+	overlap    string
+	optional   *OptionalFields
+}
+
+// NewContainment is a containment constructor
+func NewContainment(container, cOrient, contained, dOrient, pos, overlap []byte) (*Containment, error) {
+	return newContainment(newNameTable(), container, cOrient, contained, dOrient, pos, overlap)
+}
+
+// newContainment creates a containment with its segment names interned in the given name table
+func newContainment(names *nameTable, container, cOrient, contained, dOrient, pos, overlap []byte) (*Containment, error) {
+	if bytes.ContainsAny(container, "+-*= ") {
+		return nil, fieldErrorf(1, "Segment name can't contain +/-/*/= or whitespace")
+	}
+	if bytes.ContainsAny(contained, "+-*= ") {
+		return nil, fieldErrorf(3, "Segment name can't contain +/-/*/= or whitespace")
+	}
+	containment := new(Containment)
+	containment.names = names
+	containment.recordType = "C"
+	cori, dori := string(cOrient), string(dOrient)
+	if (cori == "+") || (cori == "-") {
+		containment.container = containment.names.step(container, cori)
+	} else {
+		return nil, fieldErrorf(2, "Container orientation field must be either + or -")
+	}
+	if (dori == "+") || (dori == "-") {
+		containment.contained = containment.names.step(contained, dori)
+	} else {
+		return nil, fieldErrorf(4, "Contained orientation field must be either + or -")
+	}
+	p, err := strconv.Atoi(string(pos))
+	if err != nil || p < 0 {
+		return nil, fieldErrorf(5, "Containment position must be a non-negative integer: %v", string(pos))
+	}
+	containment.pos = p
+	containment.overlap = string(overlap)
+	return containment, nil
+}
+
+// AddOptionalFields adds a set of optional fields to a containment
+func (containment *Containment) AddOptionalFields(oFs *OptionalFields) {
+	containment.optional = oFs
+}
+
+// GetOptionalFields returns the optional fields of a containment (an empty set is added if there are none, so tags can be set on it)
+func (containment *Containment) GetOptionalFields() *OptionalFields {
+	if containment.optional == nil {
+		containment.optional = new(OptionalFields)
+	}
+	return containment.optional
+}
+
+// GetRecordType returns the record type of a containment (C)
+func (containment *Containment) GetRecordType() string {
+	return containment.recordType
+}
+
+// GetContainer returns the name and orientation (+/-) of the container segment
+func (containment *Containment) GetContainer() ([]byte, string) {
+	return []byte(containment.names.name(containment.container.id())), containment.container.orient()
+}
+
+// GetContained returns the name and orientation (+/-) of the contained segment
+func (containment *Containment) GetContained() ([]byte, string) {
+	return []byte(containment.names.name(containment.contained.id())), containment.contained.orient()
+}
+
+// GetOverlap returns the overlap of a containment, as a CIGAR string (or * if not given)
+func (containment *Containment) GetOverlap() string {
+	return containment.overlap
+}
+
+// GetPosition returns the 0-based position of the contained segment within the container
+func (containment *Containment) GetPosition() int {
+	return containment.pos
+}
+
+// PrintGFAline prints a GFA formatted containment line
+func (containment *Containment) PrintGFAline() string {
+	return appendOptionalFields(fmt.Sprintf("%v\t%v\t%v\t%v\t%v\t%v\t%v", containment.recordType, containment.names.name(containment.container.id()), containment.container.orient(), containment.names.name(containment.contained.id()), containment.contained.orient(), containment.pos, containment.overlap), containment.optional)
 }
 
-// A path records a graph traversal
-type path struct {
+// Add appends a containment to a specified GFA instance
+func (containment *Containment) Add(gfa *GFA) error {
+	containment.container, containment.contained = gfa.remap(containment.names, containment.container), gfa.remap(containment.names, containment.contained)
+	containment.names = gfa.names
+	gfa.containments = append(gfa.containments, containment)
+	gfa.track(containment)
+	return nil
+}
+
+// A Path records a graph traversal
+type Path struct {
 	recordType string
-	PathName   []byte
-	SegNames   [][]byte
-	overlaps   [][]byte
-	optional   *optionalFields
+	name       []byte
+	steps      []step
+	names      *nameTable
+	overlaps   string // the overlaps as written in the P line, separated by commas
+	optional   *OptionalFields
 }
 
 // NewPath is a path constructor
-func NewPath(n []byte, segs, olaps [][]byte) (*path, error) {
-	return &path{
+func NewPath(n []byte, segs, olaps [][]byte) (*Path, error) {
+	return newPath(newNameTable(), n, segs, olaps)
+}
+
+// newPath creates a path with its segment names interned in the given name table
+func newPath(names *nameTable, n []byte, segs, olaps [][]byte) (*Path, error) {
+	if len(n) == 0 || bytes.ContainsAny(n, " \t") {
+		return nil, fieldErrorf(1, "Path name can't be empty or contain whitespace")
+	}
+	steps := make([]step, len(segs))
+	for i, seg := range segs {
+		if len(seg) < 2 || (seg[len(seg)-1] != '+' && seg[len(seg)-1] != '-') {
+			return nil, fieldErrorf(2, "Path segment names must be followed by + or -: %v", string(seg))
+		}
+		steps[i] = names.step(seg[:len(seg)-1], string(seg[len(seg)-1]))
+	}
+	return &Path{
 		recordType: "P",
-		PathName:   n,
-		SegNames:   segs,
-		overlaps:   olaps,
+		name:       append([]byte(nil), n...),
+		steps:      steps,
+		names:      names,
+		overlaps:   string(bytes.Join(olaps, []byte(","))),
 	}, nil
 }
 
 // PrintGFAline prints a GFA formatted segment line
-func (path *path) PrintGFAline() string {
-	return fmt.Sprintf("%v\t%v\t%v\t%v", path.recordType, string(path.PathName), string(bytes.Join(path.SegNames, []byte(","))), string(bytes.Join(path.overlaps, []byte(","))))
+func (path *Path) PrintGFAline() string {
+	return appendOptionalFields(fmt.Sprintf("%v\t%v\t%v\t%v", path.recordType, string(path.name), string(bytes.Join(path.orientedNames(), []byte(","))), path.overlaps), path.optional)
 }
 
 // Add appends a path to a specified GFA instance
-func (path *path) Add(gfa *GFA) error {
+func (path *Path) Add(gfa *GFA) error {
+	for i, s := range path.steps {
+		path.steps[i] = gfa.remap(path.names, s)
+	}
+	path.names = gfa.names
 	gfa.paths = append(gfa.paths, path)
+	if _, ok := gfa.pathIndex[string(path.name)]; !ok {
+		gfa.pathIndex[string(path.name)] = path
+	}
+	for _, s := range path.steps {
+		// a path that visits a segment more than once is only held once for that segment
+		paths := &gfa.nodes[s.id()].paths
+		if len(*paths) == 0 || (*paths)[len(*paths)-1] != path {
+			*paths = append(*paths, path)
+		}
+	}
+	gfa.track(path)
 	return nil
 }
 
 // AddOptionalFields adds a set of optional fields to a path
-func (path *path) AddOptionalFields(oFs *optionalFields) {
+func (path *Path) AddOptionalFields(oFs *OptionalFields) {
 	path.optional = oFs
 }
 
-// The optional fields type is an effort to clean up the segment/containment/path types and have all the optional fields in one type
-type optionalFields struct {
-	readCount   string
-	fragCount   string
-	kmerCount   string
-	checksum    []byte
-	uri         string
-	printString string
-}
-
-// NewOptionalFields is an optionalFields constructor
-func NewOptionalFields(optional ...[]byte) (*optionalFields, error) {
-	oFs := new(optionalFields)
-	if len(optional) != 0 {
-		for _, field := range optional {
-			val := bytes.Split(field, []byte(":"))
-			switch string(val[0]) {
-			// segment optional fields
-			case "LN":
-				continue
-			case "RC":
-				oFs.readCount = string(val[2])
-				oFs.printString = fmt.Sprintf("RC:i:%s\t%v", oFs.readCount, oFs.printString)
-			case "FC":
-				oFs.fragCount = string(val[2])
-				oFs.printString = fmt.Sprintf("FC:i:%s\t%v", oFs.fragCount, oFs.printString)
-			case "KC":
-				oFs.kmerCount = string(val[2])
-				oFs.printString = fmt.Sprintf("KC:i:%s\t%v", oFs.kmerCount, oFs.printString)
-			case "SH":
-				oFs.checksum = val[2]
-				oFs.printString = fmt.Sprintf("SH:H:%s\t%v", oFs.checksum, oFs.printString)
-			case "UR":
-				oFs.uri = string(val[2])
-				oFs.printString = fmt.Sprintf("UR:Z:%v\t%v", oFs.uri, oFs.printString)
-			// TODO: add optional fields for links and containments
-			default:
-				continue
-				//return nil, fmt.Errorf("Don't recognise optional field: %v", string(field))
-			}
-		}
-	} else {
-		return nil, fmt.Errorf("No optional fields supplied")
+// GetOptionalFields returns the optional fields of a path (an empty set is added if there are none, so tags can be set on it)
+func (path *Path) GetOptionalFields() *OptionalFields {
+	if path.optional == nil {
+		path.optional = new(OptionalFields)
+	}
+	return path.optional
+}
+
+// GetRecordType returns the record type of a path (P)
+func (path *Path) GetRecordType() string {
+	return path.recordType
+}
+
+// GetName returns the name of a path
+func (path *Path) GetName() []byte {
+	return path.name
+}
+
+// GetSegments returns the names of the segments in a path, without their orientations
+func (path *Path) GetSegments() [][]byte {
+	segs := make([][]byte, len(path.steps))
+	for i, s := range path.steps {
+		segs[i] = []byte(path.names.name(s.id()))
 	}
-	// trim the final tab from the printString
-	oFs.printString = strings.Trim(oFs.printString, "\t")
-	return oFs, nil
+	return segs
+}
+
+// orientedNames returns the segments of a path as names followed by their orientations (e.g. 1+)
+func (path *Path) orientedNames() [][]byte {
+	segs := make([][]byte, len(path.steps))
+	for i, s := range path.steps {
+		segs[i] = []byte(path.names.name(s.id()) + s.orient())
+	}
+	return segs
+}
+
+// GetOrientations returns the orientation (+/-) of each segment in a path
+func (path *Path) GetOrientations() []string {
+	orients := make([]string, len(path.steps))
+	for i, s := range path.steps {
+		orients[i] = s.orient()
+	}
+	return orients
+}
+
+// GetOverlaps returns the overlaps between the segments of a path, as CIGAR strings (a single * if not given)
+func (path *Path) GetOverlaps() []string {
+	return strings.Split(path.overlaps, ",")
 }
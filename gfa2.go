@@ -0,0 +1,546 @@
+package gfa
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// GetEdges returns a slice of all the edges held in the GFA instance
+func (gfa *GFA) GetEdges() ([]*Edge, error) {
+	return gfa.edges, nil
+}
+
+// GetFragments returns a slice of all the fragments held in the GFA instance
+func (gfa *GFA) GetFragments() ([]*Fragment, error) {
+	return gfa.fragments, nil
+}
+
+// GetGaps returns a slice of all the gaps held in the GFA instance
+func (gfa *GFA) GetGaps() ([]*Gap, error) {
+	return gfa.gaps, nil
+}
+
+// GetGroups returns a slice of all the ordered and unordered groups held in the GFA instance
+func (gfa *GFA) GetGroups() ([]*Group, error) {
+	return gfa.groups, nil
+}
+
+// validateGFA2 checks that the GFA2 records only reference identifiers held in the GFA instance
+func (gfa *GFA) validateGFA2() error {
+	// GFA2 segments, edges, gaps and groups share a single namespace
+	ids := make(map[string]*Segment)
+	for _, seg := range gfa.segments {
+		ids[seg.names.name(seg.id)] = seg
+	}
+	elements := make(map[string]struct{})
+	addID := func(id []byte) error {
+		if string(id) == "*" {
+			return nil
+		}
+		if _, ok := ids[string(id)]; ok {
+			return fmt.Errorf("Duplicate identifier in GFA instance: %v", string(id))
+		}
+		if _, ok := elements[string(id)]; ok {
+			return fmt.Errorf("Duplicate identifier in GFA instance: %v", string(id))
+		}
+		elements[string(id)] = struct{}{}
+		return nil
+	}
+	for _, edge := range gfa.edges {
+		if err := addID(edge.ID); err != nil {
+			return err
+		}
+		seg1, ok := ids[string(edge.Sid1)]
+		if !ok {
+			return fmt.Errorf("Edge references an unknown segment: %v", string(edge.Sid1))
+		}
+		seg2, ok := ids[string(edge.Sid2)]
+		if !ok {
+			return fmt.Errorf("Edge references an unknown segment: %v", string(edge.Sid2))
+		}
+		if err := checkPositions(seg1, edge.beg1, edge.end1); err != nil {
+			return fmt.Errorf("Edge %v has bad positions: %v", string(edge.ID), err)
+		}
+		if err := checkPositions(seg2, edge.beg2, edge.end2); err != nil {
+			return fmt.Errorf("Edge %v has bad positions: %v", string(edge.ID), err)
+		}
+	}
+	for _, gap := range gfa.gaps {
+		if err := addID(gap.ID); err != nil {
+			return err
+		}
+		if _, ok := ids[string(gap.Sid1)]; !ok {
+			return fmt.Errorf("Gap references an unknown segment: %v", string(gap.Sid1))
+		}
+		if _, ok := ids[string(gap.Sid2)]; !ok {
+			return fmt.Errorf("Gap references an unknown segment: %v", string(gap.Sid2))
+		}
+	}
+	for _, fragment := range gfa.fragments {
+		seg, ok := ids[string(fragment.Sid)]
+		if !ok {
+			return fmt.Errorf("Fragment references an unknown segment: %v", string(fragment.Sid))
+		}
+		if err := checkPositions(seg, fragment.sbeg, fragment.send); err != nil {
+			return fmt.Errorf("Fragment of %v has bad positions: %v", string(fragment.Sid), err)
+		}
+	}
+	for _, group := range gfa.groups {
+		if err := addID(group.ID); err != nil {
+			return err
+		}
+	}
+	// groups can reference segments, edges, gaps and other groups
+	for _, group := range gfa.groups {
+		for _, item := range group.Items {
+			if group.recordType == "O" {
+				item = item[:len(item)-1]
+			}
+			_, isSeg := ids[string(item)]
+			_, isElement := elements[string(item)]
+			if !isSeg && !isElement {
+				return fmt.Errorf("Group references an unknown identifier: %v", string(item))
+			}
+		}
+	}
+	return nil
+}
+
+// checkPositions checks that a begin/end pair of positions lie within a segment
+func checkPositions(seg *Segment, beg, end Position) error {
+	if beg.Offset > end.Offset {
+		return fmt.Errorf("begin position (%v) is after end position (%v)", beg, end)
+	}
+	for _, pos := range []Position{beg, end} {
+		if pos.Offset > seg.Length {
+			return fmt.Errorf("position %v is beyond the length of segment %v", pos, string(seg.GetName()))
+		}
+		if pos.IsEnd != (pos.Offset == seg.Length) {
+			return fmt.Errorf("position %v must only be marked with $ if it is the end of segment %v", pos, string(seg.GetName()))
+		}
+	}
+	return nil
+}
+
+// checkGFA2ID checks that a GFA2 identifier is a single printable word
+func checkGFA2ID(id []byte) error {
+	if len(id) == 0 {
+		return fmt.Errorf("Identifier can't be empty")
+	}
+	if bytes.ContainsAny(id, " \t") {
+		return fmt.Errorf("Identifier can't contain whitespace: %v", string(id))
+	}
+	return nil
+}
+
+// parseReference splits a GFA2 reference (an identifier followed by + or -) into its identifier and orientation,
+// the identifier is copied so that it doesn't hold on to the line it was read from
+func parseReference(ref []byte) ([]byte, string, error) {
+	if len(ref) < 2 {
+		return nil, "", fmt.Errorf("Reference must be an identifier followed by + or -: %v", string(ref))
+	}
+	orient := string(ref[len(ref)-1])
+	if (orient != "+") && (orient != "-") {
+		return nil, "", fmt.Errorf("Reference must be an identifier followed by + or -: %v", string(ref))
+	}
+	return append([]byte(nil), ref[:len(ref)-1]...), orient, nil
+}
+
+// copyItems copies the items of a group, so that they don't hold on to the line they were read from
+func copyItems(items [][]byte) [][]byte {
+	copied := make([][]byte, len(items))
+	for i, item := range items {
+		copied[i] = append([]byte(nil), item...)
+	}
+	return copied
+}
+
+// A Position is an offset into a GFA2 segment, flagged if it is the end of the segment ($)
+type Position struct {
+	Offset int
+	IsEnd  bool
+}
+
+// parsePosition converts a GFA2 position field (e.g. 100 or 150$) to a Position
+func parsePosition(pos []byte) (Position, error) {
+	p := Position{}
+	if bytes.HasSuffix(pos, []byte("$")) {
+		p.IsEnd = true
+		pos = pos[:len(pos)-1]
+	}
+	offset, err := strconv.Atoi(string(pos))
+	if err != nil || offset < 0 {
+		return p, fmt.Errorf("Position must be a non-negative integer, optionally followed by $: %v", string(pos))
+	}
+	p.Offset = offset
+	return p, nil
+}
+
+// String returns the GFA2 formatted Position
+func (p Position) String() string {
+	if p.IsEnd {
+		return fmt.Sprintf("%d$", p.Offset)
+	}
+	return strconv.Itoa(p.Offset)
+}
+
+// parsePositions converts a set of GFA2 position fields, where first is the field number of the first position in the GFA line
+func parsePositions(first int, fields ...[]byte) ([]Position, error) {
+	positions := make([]Position, len(fields))
+	for i, field := range fields {
+		pos, err := parsePosition(field)
+		if err != nil {
+			return nil, newFieldError(first+i, err)
+		}
+		positions[i] = pos
+	}
+	return positions, nil
+}
+
+// An Edge is a GFA2 record that aligns a region of one oriented segment to a region of another
+type Edge struct {
+	recordType string
+	ID         []byte
+	Sid1       []byte
+	sid1Orient string
+	Sid2       []byte
+	sid2Orient string
+	beg1       Position
+	end1       Position
+	beg2       Position
+	end2       Position
+	alignment  string
+	optional   *OptionalFields
+}
+
+// NewEdge is an edge constructor
+func NewEdge(id, sid1, sid2, beg1, end1, beg2, end2, alignment []byte) (*Edge, error) {
+	if err := checkGFA2ID(id); err != nil {
+		return nil, newFieldError(1, err)
+	}
+	edge := &Edge{recordType: "E", ID: append([]byte(nil), id...), alignment: string(alignment)}
+	var err error
+	if edge.Sid1, edge.sid1Orient, err = parseReference(sid1); err != nil {
+		return nil, newFieldError(2, err)
+	}
+	if edge.Sid2, edge.sid2Orient, err = parseReference(sid2); err != nil {
+		return nil, newFieldError(3, err)
+	}
+	positions, err := parsePositions(4, beg1, end1, beg2, end2)
+	if err != nil {
+		return nil, err
+	}
+	edge.beg1, edge.end1, edge.beg2, edge.end2 = positions[0], positions[1], positions[2], positions[3]
+	return edge, nil
+}
+
+// AddOptionalFields adds a set of optional fields to an edge
+func (edge *Edge) AddOptionalFields(oFs *OptionalFields) {
+	edge.optional = oFs
+}
+
+// GetOptionalFields returns the optional fields of an edge (an empty set is added if there are none, so tags can be set on it)
+func (edge *Edge) GetOptionalFields() *OptionalFields {
+	if edge.optional == nil {
+		edge.optional = new(OptionalFields)
+	}
+	return edge.optional
+}
+
+// GetRecordType returns the record type of an edge (E)
+func (edge *Edge) GetRecordType() string {
+	return edge.recordType
+}
+
+// GetID returns the identifier of an edge (* if it has none)
+func (edge *Edge) GetID() []byte {
+	return edge.ID
+}
+
+// GetSegments returns the names and orientations (+/-) of the two segments aligned by an edge
+func (edge *Edge) GetSegments() ([]byte, string, []byte, string) {
+	return edge.Sid1, edge.sid1Orient, edge.Sid2, edge.sid2Orient
+}
+
+// GetPositions returns the begin and end positions of the aligned region on the first and then the second segment of an edge
+func (edge *Edge) GetPositions() (Position, Position, Position, Position) {
+	return edge.beg1, edge.end1, edge.beg2, edge.end2
+}
+
+// GetAlignment returns the alignment of an edge, as a CIGAR string, a trace or *
+func (edge *Edge) GetAlignment() string {
+	return edge.alignment
+}
+
+// PrintGFAline prints a GFA formatted edge line
+func (edge *Edge) PrintGFAline() string {
+	line := fmt.Sprintf("%v\t%v\t%v%v\t%v%v\t%v\t%v\t%v\t%v\t%v", edge.recordType, string(edge.ID), string(edge.Sid1), edge.sid1Orient, string(edge.Sid2), edge.sid2Orient, edge.beg1, edge.end1, edge.beg2, edge.end2, edge.alignment)
+	return appendOptionalFields(line, edge.optional)
+}
+
+// Add appends an edge to a specified GFA instance
+func (edge *Edge) Add(gfa *GFA) error {
+	gfa.edges = append(gfa.edges, edge)
+	gfa.track(edge)
+	return nil
+}
+
+// A Fragment is a GFA2 record that aligns an external sequence (e.g. a read) to a segment
+type Fragment struct {
+	recordType     string
+	Sid            []byte
+	External       []byte
+	externalOrient string
+	sbeg           Position
+	send           Position
+	fbeg           Position
+	fend           Position
+	alignment      string
+	optional       *OptionalFields
+}
+
+// NewFragment is a fragment constructor
+func NewFragment(sid, external, sbeg, send, fbeg, fend, alignment []byte) (*Fragment, error) {
+	if err := checkGFA2ID(sid); err != nil {
+		return nil, newFieldError(1, err)
+	}
+	fragment := &Fragment{recordType: "F", Sid: append([]byte(nil), sid...), alignment: string(alignment)}
+	var err error
+	if fragment.External, fragment.externalOrient, err = parseReference(external); err != nil {
+		return nil, newFieldError(2, err)
+	}
+	positions, err := parsePositions(3, sbeg, send, fbeg, fend)
+	if err != nil {
+		return nil, err
+	}
+	fragment.sbeg, fragment.send, fragment.fbeg, fragment.fend = positions[0], positions[1], positions[2], positions[3]
+	return fragment, nil
+}
+
+// AddOptionalFields adds a set of optional fields to a fragment
+func (fragment *Fragment) AddOptionalFields(oFs *OptionalFields) {
+	fragment.optional = oFs
+}
+
+// GetOptionalFields returns the optional fields of a fragment (an empty set is added if there are none, so tags can be set on it)
+func (fragment *Fragment) GetOptionalFields() *OptionalFields {
+	if fragment.optional == nil {
+		fragment.optional = new(OptionalFields)
+	}
+	return fragment.optional
+}
+
+// GetRecordType returns the record type of a fragment (F)
+func (fragment *Fragment) GetRecordType() string {
+	return fragment.recordType
+}
+
+// GetSegment returns the name of the segment a fragment is aligned to
+func (fragment *Fragment) GetSegment() []byte {
+	return fragment.Sid
+}
+
+// GetExternal returns the identifier and orientation (+/-) of the external sequence of a fragment
+func (fragment *Fragment) GetExternal() ([]byte, string) {
+	return fragment.External, fragment.externalOrient
+}
+
+// GetPositions returns the begin and end positions of the aligned region on the segment and then the external sequence of a fragment
+func (fragment *Fragment) GetPositions() (Position, Position, Position, Position) {
+	return fragment.sbeg, fragment.send, fragment.fbeg, fragment.fend
+}
+
+// GetAlignment returns the alignment of a fragment, as a CIGAR string, a trace or *
+func (fragment *Fragment) GetAlignment() string {
+	return fragment.alignment
+}
+
+// PrintGFAline prints a GFA formatted fragment line
+func (fragment *Fragment) PrintGFAline() string {
+	line := fmt.Sprintf("%v\t%v\t%v%v\t%v\t%v\t%v\t%v\t%v", fragment.recordType, string(fragment.Sid), string(fragment.External), fragment.externalOrient, fragment.sbeg, fragment.send, fragment.fbeg, fragment.fend, fragment.alignment)
+	return appendOptionalFields(line, fragment.optional)
+}
+
+// Add appends a fragment to a specified GFA instance
+func (fragment *Fragment) Add(gfa *GFA) error {
+	gfa.fragments = append(gfa.fragments, fragment)
+	gfa.track(fragment)
+	return nil
+}
+
+// A Gap is a GFA2 record that gives the estimated distance between two oriented segments
+type Gap struct {
+	recordType string
+	ID         []byte
+	Sid1       []byte
+	sid1Orient string
+	Sid2       []byte
+	sid2Orient string
+	dist       int
+	variance   string // either an integer or * if not known
+	optional   *OptionalFields
+}
+
+// NewGap is a gap constructor
+func NewGap(id, sid1, sid2, dist, variance []byte) (*Gap, error) {
+	if err := checkGFA2ID(id); err != nil {
+		return nil, newFieldError(1, err)
+	}
+	gap := &Gap{recordType: "G", ID: append([]byte(nil), id...)}
+	var err error
+	if gap.Sid1, gap.sid1Orient, err = parseReference(sid1); err != nil {
+		return nil, newFieldError(2, err)
+	}
+	if gap.Sid2, gap.sid2Orient, err = parseReference(sid2); err != nil {
+		return nil, newFieldError(3, err)
+	}
+	if gap.dist, err = strconv.Atoi(string(dist)); err != nil {
+		return nil, fieldErrorf(4, "Gap distance must be an integer: %v", string(dist))
+	}
+	if string(variance) != "*" {
+		if _, err := strconv.Atoi(string(variance)); err != nil {
+			return nil, fieldErrorf(5, "Gap variance must be an integer or *: %v", string(variance))
+		}
+	}
+	gap.variance = string(variance)
+	return gap, nil
+}
+
+// GetDistance returns the estimated distance between the two segments of a gap
+func (gap *Gap) GetDistance() int {
+	return gap.dist
+}
+
+// AddOptionalFields adds a set of optional fields to a gap
+func (gap *Gap) AddOptionalFields(oFs *OptionalFields) {
+	gap.optional = oFs
+}
+
+// GetOptionalFields returns the optional fields of a gap (an empty set is added if there are none, so tags can be set on it)
+func (gap *Gap) GetOptionalFields() *OptionalFields {
+	if gap.optional == nil {
+		gap.optional = new(OptionalFields)
+	}
+	return gap.optional
+}
+
+// GetRecordType returns the record type of a gap (G)
+func (gap *Gap) GetRecordType() string {
+	return gap.recordType
+}
+
+// GetID returns the identifier of a gap (* if it has none)
+func (gap *Gap) GetID() []byte {
+	return gap.ID
+}
+
+// GetSegments returns the names and orientations (+/-) of the two segments either side of a gap
+func (gap *Gap) GetSegments() ([]byte, string, []byte, string) {
+	return gap.Sid1, gap.sid1Orient, gap.Sid2, gap.sid2Orient
+}
+
+// GetVariance returns the variance of the gap distance, ok is false if the variance is not known (*)
+func (gap *Gap) GetVariance() (int, bool) {
+	variance, err := strconv.Atoi(gap.variance)
+	return variance, err == nil
+}
+
+// PrintGFAline prints a GFA formatted gap line
+func (gap *Gap) PrintGFAline() string {
+	line := fmt.Sprintf("%v\t%v\t%v%v\t%v%v\t%v\t%v", gap.recordType, string(gap.ID), string(gap.Sid1), gap.sid1Orient, string(gap.Sid2), gap.sid2Orient, gap.dist, gap.variance)
+	return appendOptionalFields(line, gap.optional)
+}
+
+// Add appends a gap to a specified GFA instance
+func (gap *Gap) Add(gfa *GFA) error {
+	gfa.gaps = append(gfa.gaps, gap)
+	gfa.track(gap)
+	return nil
+}
+
+// A Group is a GFA2 record collecting other records, either as an ordered path (O) or an unordered set (U)
+type Group struct {
+	recordType string
+	ID         []byte
+	Items      [][]byte // the references (O) or identifiers (U) in the group
+	optional   *OptionalFields
+}
+
+// NewOrderedGroup is a constructor for an ordered group, where each item is an identifier followed by + or -
+func NewOrderedGroup(id []byte, items [][]byte) (*Group, error) {
+	if err := checkGFA2ID(id); err != nil {
+		return nil, newFieldError(1, err)
+	}
+	if len(items) == 0 {
+		return nil, fieldErrorf(2, "Ordered group must contain at least one reference")
+	}
+	for _, item := range items {
+		if _, _, err := parseReference(item); err != nil {
+			return nil, newFieldError(2, err)
+		}
+	}
+	return &Group{recordType: "O", ID: append([]byte(nil), id...), Items: copyItems(items)}, nil
+}
+
+// NewUnorderedGroup is a constructor for an unordered group
+func NewUnorderedGroup(id []byte, items [][]byte) (*Group, error) {
+	if err := checkGFA2ID(id); err != nil {
+		return nil, newFieldError(1, err)
+	}
+	if len(items) == 0 {
+		return nil, fieldErrorf(2, "Unordered group must contain at least one identifier")
+	}
+	for _, item := range items {
+		if err := checkGFA2ID(item); err != nil {
+			return nil, newFieldError(2, err)
+		}
+	}
+	return &Group{recordType: "U", ID: append([]byte(nil), id...), Items: copyItems(items)}, nil
+}
+
+// IsOrdered returns true if the group is an ordered group (O)
+func (group *Group) IsOrdered() bool {
+	return group.recordType == "O"
+}
+
+// AddOptionalFields adds a set of optional fields to a group
+func (group *Group) AddOptionalFields(oFs *OptionalFields) {
+	group.optional = oFs
+}
+
+// GetOptionalFields returns the optional fields of a group (an empty set is added if there are none, so tags can be set on it)
+func (group *Group) GetOptionalFields() *OptionalFields {
+	if group.optional == nil {
+		group.optional = new(OptionalFields)
+	}
+	return group.optional
+}
+
+// GetRecordType returns the record type of a group (O or U)
+func (group *Group) GetRecordType() string {
+	return group.recordType
+}
+
+// GetID returns the identifier of a group (* if it has none)
+func (group *Group) GetID() []byte {
+	return group.ID
+}
+
+// GetItems returns the references (ordered group) or identifiers (unordered group) in a group
+func (group *Group) GetItems() [][]byte {
+	return group.Items
+}
+
+// PrintGFAline prints a GFA formatted group line
+func (group *Group) PrintGFAline() string {
+	line := fmt.Sprintf("%v\t%v\t%v", group.recordType, string(group.ID), string(bytes.Join(group.Items, []byte(" "))))
+	return appendOptionalFields(line, group.optional)
+}
+
+// Add appends a group to a specified GFA instance
+func (group *Group) Add(gfa *GFA) error {
+	gfa.groups = append(gfa.groups, group)
+	gfa.track(group)
+	return nil
+}
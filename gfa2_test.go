@@ -0,0 +1,170 @@
+package gfa
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+var testGFA2 = strings.Join([]string{
+	"H\tVN:Z:2.0",
+	"S\t1\t8\tCGATGCAA",
+	"S\t2\t10\tTGCAAAGTAC",
+	"S\t3\t21\tTGCAACGTATAGACTTGTCAC",
+	"E\te1\t1+\t2+\t3\t8$\t0\t5\t5M",
+	"E\te2\t3+\t2-\t0\t10\t0\t10$\t*",
+	"F\t2\tread1+\t0\t10$\t20\t30\t*",
+	"G\tg1\t1+\t3-\t250\t*",
+	"O\tp1\t1+ 2+",
+	"U\tu1\t1 3 e1",
+}, "\n") + "\n"
+
+// create the GFA2 record types and add them to a GFA instance
+func TestGFA2records(t *testing.T) {
+	myGFA := NewGFA()
+	if err := myGFA.AddVersion(2); err != nil {
+		t.Fatal(err)
+	}
+	t.Log(myGFA.PrintHeader())
+	seg, err := NewGFA2Segment([]byte("1"), []byte("8"), []byte("CGATGCAA"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seg2, err := NewGFA2Segment([]byte("2"), []byte("10"), []byte("*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seg2.PrintGFAline() != "S\t2\t10\t*" {
+		t.Fatalf("GFA2 segment not formatted correctly: %v", seg2.PrintGFAline())
+	}
+	edge, err := NewEdge([]byte("e1"), []byte("1+"), []byte("2+"), []byte("3"), []byte("8$"), []byte("0"), []byte("5"), []byte("5M"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edge.PrintGFAline() != "E\te1\t1+\t2+\t3\t8$\t0\t5\t5M" {
+		t.Fatalf("edge not formatted correctly: %v", edge.PrintGFAline())
+	}
+	gap, err := NewGap([]byte("*"), []byte("1+"), []byte("2-"), []byte("100"), []byte("*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	group, err := NewOrderedGroup([]byte("p1"), [][]byte{[]byte("1+"), []byte("e1+"), []byte("2+")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []Record{seg, seg2, edge, gap, group} {
+		if err := line.Add(myGFA); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := myGFA.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	t.Log("checking GFA1 records are not accepted in a GFA2 instance:")
+	link, _ := NewLink([]byte("1"), []byte("+"), []byte("2"), []byte("+"), []byte("0M"))
+	link.Add(myGFA)
+	if err := myGFA.Validate(); err != nil {
+		t.Log(err)
+	} else {
+		t.Fatal("GFA2 instance with a link passed validation")
+	}
+	t.Log("testing some bad GFA2 records:")
+	if _, err := NewEdge([]byte("e2"), []byte("1"), []byte("2+"), []byte("0"), []byte("1"), []byte("0"), []byte("1"), []byte("*")); err != nil {
+		t.Log(err)
+	} else {
+		t.Fatal("edge with unoriented reference was accepted")
+	}
+	if _, err := NewGap([]byte("g2"), []byte("1+"), []byte("2+"), []byte("1x"), []byte("*")); err != nil {
+		t.Log(err)
+	} else {
+		t.Fatal("gap with bad distance was accepted")
+	}
+	if _, err := NewUnorderedGroup([]byte("u2"), nil); err != nil {
+		t.Log(err)
+	} else {
+		t.Fatal("empty group was accepted")
+	}
+}
+
+// check the GFA2 edge positions are validated against the segment lengths
+func TestGFA2positions(t *testing.T) {
+	myGFA := NewGFA()
+	if err := myGFA.AddVersion(2); err != nil {
+		t.Fatal(err)
+	}
+	seg, _ := NewGFA2Segment([]byte("1"), []byte("8"), []byte("CGATGCAA"))
+	seg2, _ := NewGFA2Segment([]byte("2"), []byte("10"), []byte("TGCAAAGTAC"))
+	seg.Add(myGFA)
+	seg2.Add(myGFA)
+	edge, err := NewEdge([]byte("e1"), []byte("1+"), []byte("2+"), []byte("3"), []byte("8"), []byte("0"), []byte("5"), []byte("*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	edge.Add(myGFA)
+	if err := myGFA.Validate(); err != nil {
+		t.Log(err)
+	} else {
+		t.Fatal("edge end position without $ passed validation")
+	}
+}
+
+// read a GFA2 file and write it back out
+func TestReadGFA2(t *testing.T) {
+	reader, err := NewReader(strings.NewReader(testGFA2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	myGFA := reader.CollectGFA()
+	if myGFA.GetVersion() != 2 {
+		t.Fatal("GFA2 version was not recognised")
+	}
+	for {
+		line, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := line.Add(myGFA); err != nil {
+			t.Fatal(err)
+		}
+	}
+	edges, _ := myGFA.GetEdges()
+	fragments, _ := myGFA.GetFragments()
+	gaps, _ := myGFA.GetGaps()
+	groups, _ := myGFA.GetGroups()
+	if len(edges) != 2 || len(fragments) != 1 || len(gaps) != 1 || len(groups) != 2 {
+		t.Fatal("did not read all the GFA2 records")
+	}
+	if !groups[0].IsOrdered() || groups[1].IsOrdered() {
+		t.Fatal("group types not recognised")
+	}
+	if gaps[0].GetDistance() != 250 {
+		t.Fatal("gap distance not read correctly")
+	}
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, myGFA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := myGFA.WriteGFAContent(writer); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(testGFA2), "\n") {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Fatalf("GFA2 line was not written back out: %v", line)
+		}
+	}
+	t.Log("checking a GFA1 line is rejected in a GFA2 file:")
+	reader, err = NewReader(strings.NewReader("H\tVN:Z:2.0\nL\t1\t+\t2\t+\t0M\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.Read(); err != nil {
+		t.Log(err)
+	} else {
+		t.Fatal("GFA1 link line was accepted in a GFA2 file")
+	}
+}
@@ -1,6 +1,8 @@
 package gfa
 
 import (
+	"io"
+	"strings"
 	"testing"
 )
 
@@ -103,3 +105,124 @@ func TestGFAvalidity(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// create a containment, add it to a GFA instance and validate it
+func TestNewContainment(t *testing.T) {
+	myGFA := NewGFA()
+	if err := myGFA.AddVersion(1); err != nil {
+		t.Fatal(err)
+	}
+	seg, _ := NewSegment([]byte("1"), []byte("aaaaatgacgt"))
+	seg2, _ := NewSegment([]byte("2"), []byte("atgac"))
+	if err := seg.Add(myGFA); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewContainment([]byte("1"), []byte("+"), []byte("2"), []byte("+"), []byte("4"), []byte("5M"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Add(myGFA); err != nil {
+		t.Fatal(err)
+	}
+	t.Log(c.PrintGFAline())
+	if c.PrintGFAline() != "C\t1\t+\t2\t+\t4\t5M" {
+		t.Fatal("containment line not formatted correctly")
+	}
+	t.Log("checking validation fails for a missing contained segment:")
+	if err := myGFA.Validate(); err != nil {
+		t.Log(err)
+	} else {
+		t.Fatal("GFA with an unknown contained segment passed validation")
+	}
+	if err := seg2.Add(myGFA); err != nil {
+		t.Fatal(err)
+	}
+	if err := myGFA.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	containments, err := myGFA.GetContainments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containments) != 1 || containments[0].GetPosition() != 4 {
+		t.Fatal("could not retrieve containment from GFA instance")
+	}
+	t.Log("testing a bad containment position:")
+	if _, err := NewContainment([]byte("1"), []byte("+"), []byte("2"), []byte("+"), []byte("x"), []byte("5M")); err != nil {
+		t.Log(err)
+	} else {
+		t.Fatal("bad containment position was accepted")
+	}
+}
+
+// test the accessors of the records returned by a reader
+func TestRecordAccessors(t *testing.T) {
+	input := "H\tVN:Z:1\n# a comment\nS\t1\tACGT\tRC:i:5\nS\t2\tTTGA\nL\t1\t+\t2\t-\t2M\nP\tp1\t1+,2-\t2M\n"
+	reader, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordTypes := ""
+	for {
+		var record Record
+		record, err = reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		recordTypes += record.GetRecordType()
+		switch r := record.(type) {
+		case *Segment:
+			if string(r.GetName()) == "1" && (string(r.GetSequence()) != "ACGT" || r.GetLength() != 4 || !r.GetOptionalFields().Has("RC")) {
+				t.Fatal("segment accessors gave the wrong values")
+			}
+		case *Link:
+			from, fOrient := r.GetFrom()
+			to, tOrient := r.GetTo()
+			if string(from) != "1" || fOrient != "+" || string(to) != "2" || tOrient != "-" || r.GetOverlap() != "2M" {
+				t.Fatal("link accessors gave the wrong values")
+			}
+		case *Path:
+			segs, orients := r.GetSegments(), r.GetOrientations()
+			if string(r.GetName()) != "p1" || string(segs[1]) != "2" || orients[1] != "-" || r.GetOverlaps()[0] != "2M" {
+				t.Fatal("path accessors gave the wrong values")
+			}
+		}
+	}
+	if recordTypes != "SSLP" {
+		t.Fatalf("unexpected record types: %v", recordTypes)
+	}
+}
+
+// test looking up segments, links and paths by segment name
+func TestLookup(t *testing.T) {
+	input := "H\tVN:Z:1\nS\t1\tACGT\nS\t2\tTTGA\nS\t3\tGG\nL\t1\t+\t2\t+\t0M\nL\t1\t+\t3\t-\t0M\nL\t2\t-\t1\t-\t0M\nP\tp1\t1+,2+\t0M\nP\tp2\t1+,3-,1+\t0M,0M\n"
+	myGFA := readTestGFA(t, input)
+	seg, ok := myGFA.GetSegment([]byte("2"))
+	if !ok || string(seg.GetSequence()) != "TTGA" {
+		t.Fatal("could not look up segment 2")
+	}
+	if _, ok := myGFA.GetSegment([]byte("4")); ok {
+		t.Fatal("found a segment that is not in the GFA")
+	}
+	if path, ok := myGFA.GetPath([]byte("p2")); !ok || len(path.GetSegments()) != 3 {
+		t.Fatal("could not look up path p2")
+	}
+	if links := myGFA.LinksFrom([]byte("1"), "+"); len(links) != 2 {
+		t.Fatalf("expected 2 links from 1+, got %d", len(links))
+	}
+	if links := myGFA.LinksFrom([]byte("1"), "-"); len(links) != 0 {
+		t.Fatalf("expected no links from 1-, got %d", len(links))
+	}
+	if links := myGFA.LinksTo([]byte("1"), "-"); len(links) != 1 || !strings.HasPrefix(links[0].PrintGFAline(), "L\t2\t") {
+		t.Fatal("expected 1 link to 1- from 2")
+	}
+	if paths := myGFA.PathsThrough([]byte("1")); len(paths) != 2 {
+		t.Fatalf("expected 2 paths through segment 1, got %d", len(paths))
+	}
+	if paths := myGFA.PathsThrough([]byte("3")); len(paths) != 1 || string(paths[0].GetName()) != "p2" {
+		t.Fatal("expected path p2 through segment 3")
+	}
+}
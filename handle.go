@@ -0,0 +1,109 @@
+package gfa
+
+import "fmt"
+
+// A Handle is a segment in one orientation, traversing a Handle in reverse reads the reverse complement of the segment
+type Handle struct {
+	Name    string
+	Reverse bool
+}
+
+// NewHandle is a Handle constructor, taking a segment name and an orientation (+/-)
+func NewHandle(name []byte, orient string) (Handle, error) {
+	switch orient {
+	case "+":
+		return Handle{Name: string(name)}, nil
+	case "-":
+		return Handle{Name: string(name), Reverse: true}, nil
+	}
+	return Handle{}, fmt.Errorf("Orientation must be either + or -: %v", orient)
+}
+
+// Flip returns the Handle for the other orientation of the segment
+func (h Handle) Flip() Handle {
+	return Handle{Name: h.Name, Reverse: !h.Reverse}
+}
+
+// Orientation returns the orientation of a Handle as + or -
+func (h Handle) Orientation() string {
+	if h.Reverse {
+		return "-"
+	}
+	return "+"
+}
+
+// String returns the segment name followed by the orientation (e.g. 1+)
+func (h Handle) String() string {
+	return h.Name + h.Orientation()
+}
+
+// GetHandles returns the Handles a link goes from and to
+func (link *Link) GetHandles() (Handle, Handle) {
+	return link.names.handle(link.from), link.names.handle(link.to)
+}
+
+// handle returns the Handle for a step, using the name table to give the segment name
+func (nt *nameTable) handle(s step) Handle {
+	return Handle{Name: nt.name(s.id()), Reverse: s.reverse()}
+}
+
+// edgeKey returns the same key for a link from one Handle to another and for the equivalent link between the flipped Handles in the other direction
+// (e.g. 1+ to 2- and 2+ to 1-)
+func edgeKey(from, to Handle) string {
+	forward := from.String() + "\t" + to.String()
+	reverse := to.Flip().String() + "\t" + from.Flip().String()
+	if reverse < forward {
+		return reverse
+	}
+	return forward
+}
+
+// Successors returns the Handles that can follow a Handle, using the links in both of the directions they can be read
+// (a link from 1+ to 2- also allows 2+ to be followed by 1-), each Handle is only returned once
+func (gfa *GFA) Successors(h Handle) []Handle {
+	successors := []Handle{}
+	seen := make(map[Handle]struct{})
+	add := func(next Handle) {
+		if _, ok := seen[next]; !ok {
+			seen[next] = struct{}{}
+			successors = append(successors, next)
+		}
+	}
+	id, ok := gfa.names.lookup(h.Name)
+	if !ok {
+		return successors
+	}
+	s := newStep(id, h.Reverse)
+	for _, link := range gfa.nodes[id].linksFrom[s&1] {
+		add(gfa.names.handle(link.to))
+	}
+	for _, link := range gfa.nodes[id].linksTo[s.flip()&1] {
+		add(gfa.names.handle(link.from.flip()))
+	}
+	return successors
+}
+
+// Predecessors returns the Handles that can come before a Handle, each Handle is only returned once
+func (gfa *GFA) Predecessors(h Handle) []Handle {
+	predecessors := gfa.Successors(h.Flip())
+	for i, p := range predecessors {
+		predecessors[i] = p.Flip()
+	}
+	return predecessors
+}
+
+// CanonicalLinks returns the links of the GFA instance with any duplicates removed, where a link is a duplicate if it joins the same Handles
+// as an earlier link, either as written or read in the other direction (e.g. L 2 - 1 - duplicates L 1 + 2 +)
+func (gfa *GFA) CanonicalLinks() []*Link {
+	links := []*Link{}
+	seen := make(map[string]struct{})
+	for _, link := range gfa.links {
+		key := edgeKey(link.GetHandles())
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		links = append(links, link)
+	}
+	return links
+}
@@ -0,0 +1,44 @@
+package gfa
+
+import (
+	"reflect"
+	"testing"
+)
+
+// test the bidirected traversal of links using Handles
+func TestHandles(t *testing.T) {
+	input := "H\tVN:Z:1\nS\t1\tACGT\nS\t2\tTTGA\nS\t3\tGG\nL\t1\t+\t2\t-\t0M\nL\t2\t+\t1\t-\t0M\nL\t3\t+\t1\t+\t0M\nL\t2\t+\t2\t+\t0M\n"
+	myGFA := readTestGFA(t, input)
+	h, err := NewHandle([]byte("1"), "+")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Flip().String() != "1-" || h.Flip().Flip() != h {
+		t.Fatal("could not flip handle")
+	}
+	if _, err := NewHandle([]byte("1"), "x"); err == nil {
+		t.Fatal("bad orientation gave a handle")
+	}
+	// 1+ -> 2- is written once and also implied by 2+ -> 1-
+	if succ := myGFA.Successors(h); !reflect.DeepEqual(succ, []Handle{{Name: "2", Reverse: true}}) {
+		t.Fatalf("unexpected successors of 1+: %v", succ)
+	}
+	if pred := myGFA.Predecessors(h); !reflect.DeepEqual(pred, []Handle{{Name: "3"}}) {
+		t.Fatalf("unexpected predecessors of 1+: %v", pred)
+	}
+	// 3+ -> 1+ implies 1- -> 3-
+	if succ := myGFA.Successors(h.Flip()); !reflect.DeepEqual(succ, []Handle{{Name: "3", Reverse: true}}) {
+		t.Fatalf("unexpected successors of 1-: %v", succ)
+	}
+	// the self loop 2+ -> 2+ also gives 2- -> 2-
+	two := Handle{Name: "2"}
+	if succ := myGFA.Successors(two); !reflect.DeepEqual(succ, []Handle{{Name: "1", Reverse: true}, {Name: "2"}}) {
+		t.Fatalf("unexpected successors of 2+: %v", succ)
+	}
+	if succ := myGFA.Successors(two.Flip()); !reflect.DeepEqual(succ, []Handle{{Name: "2", Reverse: true}}) {
+		t.Fatalf("unexpected successors of 2-: %v", succ)
+	}
+	if links := myGFA.CanonicalLinks(); len(links) != 3 {
+		t.Fatalf("expected 3 canonical links, got %d", len(links))
+	}
+}
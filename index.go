@@ -0,0 +1,311 @@
+package gfa
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// IndexSuffix is added to the name of a GFA file to give the name of its index file
+const IndexSuffix = ".gfai"
+
+// An Index records where the segment, link and path lines are in a GFA file, so that they can be fetched without reading the whole file
+//
+// offsets are byte offsets for an uncompressed file and virtual offsets for a BGZF compressed file
+type Index struct {
+	Compression Compression
+	Segments    map[string]int64   // segment name to the offset of its S line
+	Paths       map[string]int64   // path name to the offset of its P line
+	Links       map[string][]int64 // segment name to the offsets of the L lines it is an endpoint of
+}
+
+// newIndex returns an empty index
+func newIndex(compression Compression) *Index {
+	return &Index{
+		Compression: compression,
+		Segments:    make(map[string]int64),
+		Paths:       make(map[string]int64),
+		Links:       make(map[string][]int64),
+	}
+}
+
+// add records the offset of a line in the index
+func (idx *Index) add(line []byte, offset int64) {
+	fields := bytes.SplitN(line, []byte("\t"), 5)
+	if len(fields) < 2 || len(fields[0]) != 1 {
+		return
+	}
+	switch fields[0][0] {
+	case 'S':
+		idx.Segments[string(fields[1])] = offset
+	case 'P':
+		// the first path with a name is kept, as it is by GetPath
+		if _, ok := idx.Paths[string(fields[1])]; !ok {
+			idx.Paths[string(fields[1])] = offset
+		}
+	case 'L':
+		if len(fields) < 4 {
+			return
+		}
+		idx.Links[string(fields[1])] = append(idx.Links[string(fields[1])], offset)
+		if !bytes.Equal(fields[1], fields[3]) {
+			idx.Links[string(fields[3])] = append(idx.Links[string(fields[3])], offset)
+		}
+	}
+}
+
+// BuildIndex reads a GFA file (uncompressed or BGZF compressed) and indexes the offsets of its segment, link and path lines
+func BuildIndex(fileName string) (*Index, error) {
+	fh, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+	br := bufio.NewReader(fh)
+	magic, err := br.Peek(14)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	idx := newIndex(detectCompression(magic))
+	switch idx.Compression {
+	case Uncompressed:
+		var offset int64
+		for {
+			line, err := br.ReadBytes('\n')
+			if len(line) != 0 {
+				idx.add(line, offset)
+				offset += int64(len(line))
+			}
+			if err == io.EOF {
+				return idx, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	case BGZF:
+		bgzf := newBGZFReader(br)
+		for {
+			line, offset, err := bgzf.ReadLine()
+			if err == io.EOF {
+				return idx, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			idx.add(line, offset)
+		}
+	}
+	return nil, fmt.Errorf("Can't index a gzip compressed GFA file, it must be uncompressed or BGZF compressed")
+}
+
+// Write writes the index in a tab separated format
+func (idx *Index) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "#gfai\t%v\n", idx.Compression)
+	for _, name := range sortedKeys(idx.Segments) {
+		fmt.Fprintf(bw, "S\t%v\t%d\n", name, idx.Segments[name])
+	}
+	for _, name := range sortedKeys(idx.Paths) {
+		fmt.Fprintf(bw, "P\t%v\t%d\n", name, idx.Paths[name])
+	}
+	names := make([]string, 0, len(idx.Links))
+	for name := range idx.Links {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, offset := range idx.Links[name] {
+			fmt.Fprintf(bw, "L\t%v\t%d\n", name, offset)
+		}
+	}
+	return bw.Flush()
+}
+
+// sortedKeys returns the keys of an offset map in order
+func sortedKeys(offsets map[string]int64) []string {
+	keys := make([]string, 0, len(offsets))
+	for key := range offsets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ReadIndex reads an index written by Index.Write
+func ReadIndex(r io.Reader) (*Index, error) {
+	br := bufio.NewReader(r)
+	header, err := br.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("Could not read index header: %v", err)
+	}
+	var idx *Index
+	for _, compression := range []Compression{Uncompressed, BGZF} {
+		if header == fmt.Sprintf("#gfai\t%v\n", compression) {
+			idx = newIndex(compression)
+		}
+	}
+	if idx == nil {
+		return nil, fmt.Errorf("Not a GFA index: %q", header)
+	}
+	for lineNum := 2; ; lineNum++ {
+		line, err := br.ReadBytes('\n')
+		if err == io.EOF && len(line) == 0 {
+			return idx, nil
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		fields := bytes.Split(bytes.TrimSuffix(line, []byte("\n")), []byte("\t"))
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("Index line %d does not have 3 fields", lineNum)
+		}
+		offset, err := strconv.ParseInt(string(fields[2]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Index line %d has a bad offset: %v", lineNum, string(fields[2]))
+		}
+		name := string(fields[1])
+		switch string(fields[0]) {
+		case "S":
+			idx.Segments[name] = offset
+		case "P":
+			idx.Paths[name] = offset
+		case "L":
+			idx.Links[name] = append(idx.Links[name], offset)
+		default:
+			return nil, fmt.Errorf("Index line %d has an unknown record type: %v", lineNum, string(fields[0]))
+		}
+	}
+}
+
+// Save writes the index to the index file of a GFA file (the GFA file name plus IndexSuffix)
+func (idx *Index) Save(fileName string) error {
+	fh, err := os.Create(fileName + IndexSuffix)
+	if err != nil {
+		return err
+	}
+	if err := idx.Write(fh); err != nil {
+		fh.Close()
+		return err
+	}
+	return fh.Close()
+}
+
+// LoadIndex reads the index file of a GFA file (the GFA file name plus IndexSuffix)
+func LoadIndex(fileName string) (*Index, error) {
+	fh, err := os.Open(fileName + IndexSuffix)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+	return ReadIndex(fh)
+}
+
+// SetIndex gives a Reader created by Open an index of its file, so that records can be fetched by name
+func (r *Reader) SetIndex(idx *Index) error {
+	if r.file == nil {
+		return fmt.Errorf("Reader was not created by Open, so records can't be fetched from the file")
+	}
+	if idx.Compression != r.compression {
+		return fmt.Errorf("Index is for a %v file but the Reader file is %v", idx.Compression, r.compression)
+	}
+	r.index = idx
+	return nil
+}
+
+// FetchSegment uses the index to read the segment with the given name from the file
+func (r *Reader) FetchSegment(name string) (*Segment, error) {
+	if r.index == nil {
+		return nil, fmt.Errorf("Reader has no index")
+	}
+	offset, ok := r.index.Segments[name]
+	if !ok {
+		return nil, fmt.Errorf("Segment not found in index: %v", name)
+	}
+	line, err := r.fetch(offset)
+	if err != nil {
+		return nil, err
+	}
+	seg, ok := line.(*Segment)
+	if !ok {
+		return nil, fmt.Errorf("Index offset for segment %v is not a segment line", name)
+	}
+	return seg, nil
+}
+
+// FetchPath uses the index to read the path with the given name from the file
+func (r *Reader) FetchPath(name string) (*Path, error) {
+	if r.index == nil {
+		return nil, fmt.Errorf("Reader has no index")
+	}
+	offset, ok := r.index.Paths[name]
+	if !ok {
+		return nil, fmt.Errorf("Path not found in index: %v", name)
+	}
+	line, err := r.fetch(offset)
+	if err != nil {
+		return nil, err
+	}
+	path, ok := line.(*Path)
+	if !ok {
+		return nil, fmt.Errorf("Index offset for path %v is not a path line", name)
+	}
+	return path, nil
+}
+
+// FetchLinks uses the index to read all the links to or from the segment with the given name from the file
+func (r *Reader) FetchLinks(name string) ([]*Link, error) {
+	if r.index == nil {
+		return nil, fmt.Errorf("Reader has no index")
+	}
+	links := []*Link{}
+	for _, offset := range r.index.Links[name] {
+		line, err := r.fetch(offset)
+		if err != nil {
+			return nil, err
+		}
+		link, ok := line.(*Link)
+		if !ok {
+			return nil, fmt.Errorf("Index offset for a link of segment %v is not a link line", name)
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// fetch reads and parses the line at an offset in the file, without moving the Reader on from its current line
+// (the line number of a fetched line is not known, so any ParseError has a line number of 0)
+func (r *Reader) fetch(offset int64) (Record, error) {
+	var raw []byte
+	var err error
+	switch r.compression {
+	case Uncompressed:
+		raw, err = bufio.NewReader(io.NewSectionReader(r.file, offset, 1<<62)).ReadBytes('\n')
+	case BGZF:
+		// seek to the block and then skip to the start of the line
+		var gz *gzip.Reader
+		gz, err = gzip.NewReader(io.NewSectionReader(r.file, offset>>16, 1<<62))
+		if err != nil {
+			return nil, err
+		}
+		br := bufio.NewReader(gz)
+		if _, err = io.CopyN(ioutil.Discard, br, offset&0xffff); err != nil {
+			return nil, err
+		}
+		raw, err = br.ReadBytes('\n')
+	}
+	if err != nil && (err != io.EOF || len(raw) == 0) {
+		return nil, err
+	}
+	line, perr := parseLine(bytes.TrimSuffix(bytes.TrimSuffix(raw, []byte("\n")), []byte("\r")), 0, r.gfa.GetVersion(), newNameTable())
+	if perr != nil {
+		return nil, perr
+	}
+	return line, nil
+}
@@ -0,0 +1,136 @@
+package gfa
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// test indexing uncompressed and BGZF GFA files and fetching records by name
+func TestIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gfa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	input, err := ioutil.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	myGFA := readLossless(t, input)
+	// the example GFA is larger than a BGZF block, so some records will be in later blocks
+	bgzfFile := filepath.Join(dir, "example.gfa.gz")
+	writer, err := Create(bgzfFile, myGFA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := myGFA.WriteGFAContent(writer); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, fileName := range []string{testFile, bgzfFile} {
+		idx, err := BuildIndex(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// save and load the index
+		indexFile := filepath.Join(dir, filepath.Base(fileName))
+		if err := idx.Save(indexFile); err != nil {
+			t.Fatal(err)
+		}
+		if idx, err = LoadIndex(indexFile); err != nil {
+			t.Fatal(err)
+		}
+		if len(idx.Segments) != len(myGFA.segments) || len(idx.Paths) != len(myGFA.paths) {
+			t.Fatalf("index of %v has %d segments and %d paths", fileName, len(idx.Segments), len(idx.Paths))
+		}
+		reader, err := Open(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := reader.SetIndex(idx); err != nil {
+			t.Fatal(err)
+		}
+		for _, seg := range myGFA.segments {
+			fetched, err := reader.FetchSegment(string(seg.GetName()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fetched.PrintGFAline() != seg.PrintGFAline() {
+				t.Fatalf("fetched the wrong segment from %v: %v", fileName, fetched.PrintGFAline())
+			}
+		}
+		path := myGFA.paths[len(myGFA.paths)-1]
+		fetchedPath, err := reader.FetchPath(string(path.GetName()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fetchedPath.PrintGFAline() != path.PrintGFAline() {
+			t.Fatalf("fetched the wrong path from %v", fileName)
+		}
+		links, err := reader.FetchLinks("2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := 0
+		for _, link := range myGFA.links {
+			from, _ := link.GetFrom()
+			to, _ := link.GetTo()
+			if string(from) == "2" || string(to) == "2" {
+				expected++
+			}
+		}
+		if len(links) == 0 || len(links) != expected {
+			t.Fatalf("expected %d links for segment 2, got %d", expected, len(links))
+		}
+		// fetching should not affect reading the file in order
+		line, err := reader.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line.PrintGFAline() != myGFA.segments[0].PrintGFAline() {
+			t.Fatal("fetching records moved the reader on")
+		}
+		if _, err := reader.FetchSegment("not a segment"); err == nil {
+			t.Fatal("expected an error fetching a segment not in the index")
+		}
+		reader.Close()
+	}
+}
+
+// test that the index fetches the same path as GetPath when paths share a name
+func TestIndexDuplicatePath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gfa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	input := "H\tVN:Z:1\nS\t1\tACGT\nS\t2\tTT\nL\t1\t+\t2\t+\t0M\nP\tp1\t1+,2+\t0M\nP\tp1\t2-,1-\t0M\n"
+	fileName := filepath.Join(dir, "duplicate.gfa")
+	if err := ioutil.WriteFile(fileName, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+	idx, err := BuildIndex(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := Open(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if err := reader.SetIndex(idx); err != nil {
+		t.Fatal(err)
+	}
+	fetched, err := reader.FetchPath("p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, _ := readTestGFA(t, input).GetPath([]byte("p1"))
+	if fetched.PrintGFAline() != path.PrintGFAline() || path.PrintGFAline() != "P\tp1\t1+,2+\t0M" {
+		t.Fatalf("fetched %v, GetPath gave %v", fetched.PrintGFAline(), path.PrintGFAline())
+	}
+}
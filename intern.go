@@ -0,0 +1,175 @@
+package gfa
+
+// nameTableMapSize is the number of names a nameTable holds before they are indexed with a map, smaller tables (such as the ones
+// held by records that have not been added to a GFA instance) are searched in order
+const nameTableMapSize = 8
+
+// A segID is the dense integer ID given to a segment name by a nameTable
+type segID uint32
+
+// A step is a segment ID and an orientation packed into a single integer, with the orientation in the lowest bit (set for -)
+type step uint32
+
+// newStep packs a segment ID and an orientation into a step
+func newStep(id segID, reverse bool) step {
+	s := step(id) << 1
+	if reverse {
+		s |= 1
+	}
+	return s
+}
+
+// id returns the segment ID of a step
+func (s step) id() segID {
+	return segID(s >> 1)
+}
+
+// reverse returns true if a step traverses its segment in reverse
+func (s step) reverse() bool {
+	return s&1 == 1
+}
+
+// orient returns the orientation of a step as + or -
+func (s step) orient() string {
+	if s.reverse() {
+		return "-"
+	}
+	return "+"
+}
+
+// flip returns the step for the other orientation of the segment
+func (s step) flip() step {
+	return s ^ 1
+}
+
+// A nameTable interns segment names, so that each name is only held once and records can refer to segments by integer ID
+type nameTable struct {
+	names []string         // segment ID to name
+	ids   map[string]segID // name to segment ID, nil until the table holds more than nameTableMapSize names
+}
+
+// newNameTable returns an empty nameTable
+func newNameTable() *nameTable {
+	return &nameTable{}
+}
+
+// lookup returns the ID of a name, ok is false if the name has not been interned
+func (nt *nameTable) lookup(name string) (segID, bool) {
+	if nt.ids != nil {
+		id, ok := nt.ids[name]
+		return id, ok
+	}
+	for i, n := range nt.names {
+		if n == name {
+			return segID(i), true
+		}
+	}
+	return 0, false
+}
+
+// intern returns the ID of a name, giving it the next ID if it has not been interned before
+func (nt *nameTable) intern(name string) segID {
+	if id, ok := nt.lookup(name); ok {
+		return id
+	}
+	id := segID(len(nt.names))
+	nt.names = append(nt.names, name)
+	switch {
+	case nt.ids != nil:
+		nt.ids[name] = id
+	case len(nt.names) > nameTableMapSize:
+		nt.ids = make(map[string]segID, len(nt.names))
+		for i, n := range nt.names {
+			nt.ids[n] = segID(i)
+		}
+	}
+	return id
+}
+
+// step interns a segment name and returns its step in a given orientation (+/-)
+func (nt *nameTable) step(name []byte, orient string) step {
+	// names already in the table are found without converting them to a string
+	if nt.ids != nil {
+		if id, ok := nt.ids[string(name)]; ok {
+			return newStep(id, orient == "-")
+		}
+	} else {
+		for i, n := range nt.names {
+			if n == string(name) {
+				return newStep(segID(i), orient == "-")
+			}
+		}
+	}
+	return newStep(nt.intern(string(name)), orient == "-")
+}
+
+// truncate removes the names with IDs from n onwards, so that the names interned by a record that was not added can be taken back out
+func (nt *nameTable) truncate(n int) {
+	for _, name := range nt.names[n:] {
+		delete(nt.ids, name)
+	}
+	nt.names = nt.names[:n]
+}
+
+// name returns the name for an ID
+func (nt *nameTable) name(id segID) string {
+	return nt.names[id]
+}
+
+// len returns the number of names in the table
+func (nt *nameTable) len() int {
+	return len(nt.names)
+}
+
+// a segNode holds the records of a GFA instance that refer to a segment ID, so that they can be found without a name lookup
+type segNode struct {
+	seg       *Segment   // nil if the segment has only been referenced by other records
+	linksFrom [2][]*Link // the links that start from the segment, indexed by the orientation bit of a step
+	linksTo   [2][]*Link // the links that go to the segment, indexed by the orientation bit of a step
+	paths     []*Path    // the paths that include the segment, each path is only held once
+}
+
+// intern gives a segment name an ID in the name table of the GFA instance
+func (gfa *GFA) intern(name string) segID {
+	id := gfa.names.intern(name)
+	gfa.grow(id)
+	return id
+}
+
+// grow makes sure the GFA instance has a segNode for a segment ID
+func (gfa *GFA) grow(id segID) {
+	for int(id) >= len(gfa.nodes) {
+		gfa.nodes = append(gfa.nodes, segNode{})
+	}
+}
+
+// remap moves a step from the name table of a record to the name table of the GFA instance, a record read by ReadAll is already
+// interned in the name table of the GFA instance, so only the segNode is needed
+func (gfa *GFA) remap(names *nameTable, s step) step {
+	if names == gfa.names {
+		gfa.grow(s.id())
+		return s
+	}
+	return newStep(gfa.intern(names.name(s.id())), s.reverse())
+}
+
+// lookup returns the ID of a segment name in the GFA instance, ok is false if the name has not been referenced by any record
+func (gfa *GFA) lookup(name []byte) (segID, bool) {
+	return gfa.names.lookup(string(name))
+}
+
+// segment returns the segment with a given ID, or nil if there is no segment record for the ID
+func (gfa *GFA) segment(id segID) *Segment {
+	return gfa.nodes[id].seg
+}
+
+// orientBit converts an orientation (+/-) to the orientation bit of a step, ok is false for any other value
+func orientBit(orient string) (int, bool) {
+	switch orient {
+	case "+":
+		return 0, true
+	case "-":
+		return 1, true
+	}
+	return 0, false
+}
@@ -0,0 +1,156 @@
+package gfa
+
+import (
+	"bytes"
+	"io/ioutil"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+// scaleExample returns the test GFA repeated a number of times, with the segment and path names of each copy given a suffix so that
+// the copies are disjoint
+func scaleExample(tb testing.TB, copies int) []byte {
+	input, err := ioutil.ReadFile(testFile)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(input), []byte("\n"))
+	var buf bytes.Buffer
+	buf.Write(lines[0])
+	buf.WriteByte('\n')
+	for i := 0; i < copies; i++ {
+		suffix := []byte("." + strconv.Itoa(i))
+		rename := func(name []byte) []byte {
+			return append(append([]byte{}, name...), suffix...)
+		}
+		for _, line := range lines[1:] {
+			fields := bytes.Split(line, []byte("\t"))
+			switch string(fields[0]) {
+			case "S":
+				fields[1] = rename(fields[1])
+			case "L":
+				fields[1], fields[3] = rename(fields[1]), rename(fields[3])
+			case "P":
+				fields[1] = rename(fields[1])
+				steps := bytes.Split(fields[2], []byte(","))
+				for j, step := range steps {
+					steps[j] = append(rename(step[:len(step)-1]), step[len(step)-1])
+				}
+				fields[2] = bytes.Join(steps, []byte(","))
+			}
+			buf.Write(bytes.Join(fields, []byte("\t")))
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+// test that interned names are given dense IDs and are not duplicated
+func TestNameTable(t *testing.T) {
+	names := newNameTable()
+	for i := 0; i < 100; i++ {
+		if id := names.intern(strconv.Itoa(i)); id != segID(i) {
+			t.Fatalf("name %d was given ID %d", i, id)
+		}
+	}
+	for i := 99; i >= 0; i-- {
+		if id := names.intern(strconv.Itoa(i)); id != segID(i) {
+			t.Fatalf("name %d was given a new ID %d", i, id)
+		}
+		if id, ok := names.lookup(strconv.Itoa(i)); !ok || id != segID(i) {
+			t.Fatalf("could not look up name %d", i)
+		}
+	}
+	if _, ok := names.lookup("100"); ok {
+		t.Fatal("looked up a name that was never interned")
+	}
+	if names.name(42) != "42" || names.len() != 100 {
+		t.Fatal("name table does not hold the interned names")
+	}
+	s := newStep(7, true)
+	if s.id() != 7 || !s.reverse() || s.orient() != "-" || s.flip() != newStep(7, false) {
+		t.Fatalf("step does not hold its ID and orientation: %v", s)
+	}
+}
+
+// test that records added to a GFA instance are moved onto its name table and still print the names they were given
+func TestInternedRecords(t *testing.T) {
+	myGFA := readTestGFA(t, "H\tVN:Z:1\nS\ts1\tACGT\nL\ts1\t+\ts2\t-\t0M\nS\ts2\tTT\nP\tp1\ts1+,s2-\t*\nC\ts1\t+\ts2\t-\t1\t*\n")
+	if myGFA.names.len() != 2 {
+		t.Fatalf("expected 2 interned segment names, got %d", myGFA.names.len())
+	}
+	links, _ := myGFA.GetLinks()
+	if links[0].names != myGFA.names || links[0].PrintGFAline() != "L\ts1\t+\ts2\t-\t0M" {
+		t.Fatalf("link was not interned: %v", links[0].PrintGFAline())
+	}
+	paths, _ := myGFA.GetPaths()
+	if paths[0].names != myGFA.names || paths[0].PrintGFAline() != "P\tp1\ts1+,s2-\t*" {
+		t.Fatalf("path was not interned: %v", paths[0].PrintGFAline())
+	}
+	seg, _ := myGFA.GetSegment([]byte("s2"))
+	if string(seg.GetName()) != "s2" || seg.id != 1 {
+		t.Fatalf("segment was not given the ID of its first reference: %d", seg.id)
+	}
+}
+
+// test that ReadAll interns lines into the name table of its GFA instance, taking back out the names of lines that are skipped,
+// and that records returned by Read have their own name table
+func TestReaderInterning(t *testing.T) {
+	reader, err := NewReader(bytes.NewReader([]byte("H\tVN:Z:1\nL\ts1\t+\ts2\t-\t0M\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, err := reader.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if myGFA := reader.CollectGFA(); line.(*Link).names == myGFA.names || myGFA.names.len() != 0 {
+		t.Fatal("record returned by Read was interned into the name table of the reader")
+	}
+	input := "H\tVN:Z:1\nS\ts1\tACGT\nP\tp1\ts1+,s2+,s3?\t*\nL\ts1\t+\ts4\t-\t0M\n"
+	reader, err = NewReader(bytes.NewReader([]byte(input)), Lenient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	myGFA, err := reader.ReadAll(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reader.Errors()) != 1 || myGFA.names.len() != 2 || len(myGFA.nodes) != 2 {
+		t.Fatalf("names of the skipped path were kept: %v", myGFA.names.names)
+	}
+	links, _ := myGFA.GetLinks()
+	if links[0].names != myGFA.names || links[0].PrintGFAline() != "L\ts1\t+\ts4\t-\t0M" {
+		t.Fatalf("link was not interned: %v", links[0].PrintGFAline())
+	}
+	if _, ok := myGFA.lookup([]byte("s2")); ok {
+		t.Fatal("name from the skipped path was found")
+	}
+}
+
+// BenchmarkReadMemory reports the heap held by a GFA instance read from the test GFA scaled up 200 times
+// (the heap-B/seg metric is the number of bytes held per segment)
+func BenchmarkReadMemory(b *testing.B) {
+	input := scaleExample(b, 200)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		reader, err := NewReader(bytes.NewReader(input))
+		if err != nil {
+			b.Fatal(err)
+		}
+		myGFA, err := reader.ReadAll(1)
+		if err != nil {
+			b.Fatal(err)
+		}
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		segs, _ := myGFA.GetSegments()
+		b.ReportMetric(float64(after.HeapAlloc-before.HeapAlloc)/float64(len(segs)), "heap-B/seg")
+		runtime.KeepAlive(myGFA)
+	}
+}
@@ -0,0 +1,87 @@
+package gfa
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io"
+)
+
+// rawLine holds the original bytes of a line read in lossless mode
+type rawLine struct {
+	prefix []byte // any blank lines found before the line
+	line   []byte // the line as read, including its line ending
+	sum    uint64 // a checksum of the formatted record when it was read, used to detect if it has since been modified
+}
+
+// lineSum returns a checksum of a formatted GFA line
+func lineSum(line Record) uint64 {
+	h := fnv.New64a()
+	io.WriteString(h, line.PrintGFAline())
+	return h.Sum64()
+}
+
+// track records the position of a line in a GFA instance, if the GFA instance is keeping the line order
+func (gfa *GFA) track(line Record) {
+	if gfa.lossless {
+		gfa.order = append(gfa.order, line)
+	}
+}
+
+// trackVersion gives the first header line with a version the new version, or adds a header line at the top if there isn't one,
+// so that a version set with AddVersion is written by a GFA instance that is keeping the line order
+func (gfa *GFA) trackVersion(v int) {
+	if !gfa.lossless {
+		return
+	}
+	for _, line := range gfa.order {
+		if header, ok := line.(*Header); ok && header.vn != 0 {
+			header.vn, header.version = v, ""
+			return
+		}
+	}
+	gfa.order = append([]Record{&Header{recordType: "H", vn: v}}, gfa.order...)
+}
+
+// keepRaw stores the original bytes of a line read in lossless mode
+func (gfa *GFA) keepRaw(line Record, prefix, raw []byte) {
+	gfa.raw[line] = &rawLine{prefix: prefix, line: append([]byte(nil), raw...), sum: lineSum(line)}
+}
+
+// writeLossless writes every line in its original order, using the original bytes for any line that has not been modified
+// (lines that were added after reading are formatted as usual)
+func (gfa *GFA) writeLossless(w *GFAwriter) error {
+	for _, line := range gfa.order {
+		raw, ok := gfa.raw[line]
+		if !ok {
+			if err := w.Write(line); err != nil {
+				return fmt.Errorf("Can't write GFA content: %v", err)
+			}
+			continue
+		}
+		if err := w.writeRaw(raw.prefix); err != nil {
+			return fmt.Errorf("Can't write GFA content: %v", err)
+		}
+		if lineSum(line) == raw.sum {
+			if err := w.writeRaw(raw.line); err != nil {
+				return fmt.Errorf("Can't write GFA content: %v", err)
+			}
+			continue
+		}
+		// the line has been modified, so format it but keep the original line ending
+		formatted := []byte(line.PrintGFAline())
+		switch {
+		case bytes.HasSuffix(raw.line, []byte("\r\n")):
+			formatted = append(formatted, '\r', '\n')
+		case bytes.HasSuffix(raw.line, []byte("\n")):
+			formatted = append(formatted, '\n')
+		}
+		if err := w.writeRaw(formatted); err != nil {
+			return fmt.Errorf("Can't write GFA content: %v", err)
+		}
+	}
+	if err := w.writeRaw(gfa.trailer); err != nil {
+		return fmt.Errorf("Can't write GFA content: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package gfa
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+// readLossless reads a GFA in lossless mode
+func readLossless(t *testing.T, input []byte) *GFA {
+	reader, err := NewReader(bytes.NewReader(input), Lossless())
+	if err != nil {
+		t.Fatal(err)
+	}
+	myGFA := reader.CollectGFA()
+	for {
+		line, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := line.Add(myGFA); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return myGFA
+}
+
+// writeGFA writes a GFA to a byte slice
+func writeGFA(t *testing.T, myGFA *GFA) []byte {
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, myGFA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := myGFA.WriteGFAContent(writer); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// test that the example GFA is written back byte-for-byte
+func TestLosslessExample(t *testing.T) {
+	input, err := ioutil.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output := writeGFA(t, readLossless(t, input)); !bytes.Equal(input, output) {
+		t.Fatal("lossless round trip of example GFA changed the content")
+	}
+}
+
+// test that interleaved headers/comments, blank lines, tag order and line endings are kept
+func TestLosslessRoundTrip(t *testing.T) {
+	input := []byte("# first comment\r\nH\tVN:Z:1.0\tXX:Z:foo\r\nS\t1\tACGT\tRC:i:5\tLN:i:4\r\n\r\n# mid-file comment\r\nS\t2\tTTGA\r\nH\tYY:i:2\r\nL\t1\t+\t2\t-\t2M\tZZ:Z:bar\r\n\r\n")
+	myGFA := readLossless(t, input)
+	if len(myGFA.comments) != 2 {
+		t.Fatalf("expected 2 comments, got %d", len(myGFA.comments))
+	}
+	if !myGFA.header.optional.Has("YY") {
+		t.Fatal("mid-file header was not merged into the GFA header")
+	}
+	if output := writeGFA(t, myGFA); !bytes.Equal(input, output) {
+		t.Fatalf("lossless round trip changed the content:\n%q", output)
+	}
+	// a file without a final newline
+	input = []byte("H\tVN:Z:1\nS\t1\tACGT")
+	if output := writeGFA(t, readLossless(t, input)); !bytes.Equal(input, output) {
+		t.Fatalf("lossless round trip changed the content:\n%q", output)
+	}
+}
+
+// test that only modified lines are reformatted
+func TestLosslessEdit(t *testing.T) {
+	input := []byte("H\tVN:Z:1\nS\t1\tACGT\tRC:i:5\nS\t2\tTTGA\tRC:i:+3\r\n")
+	myGFA := readLossless(t, input)
+	if err := myGFA.segments[1].GetOptionalFields().SetInt("RC", 4); err != nil {
+		t.Fatal(err)
+	}
+	newSeg, err := NewSegment([]byte("3"), []byte("GG"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newSeg.Add(myGFA); err != nil {
+		t.Fatal(err)
+	}
+	output := string(writeGFA(t, myGFA))
+	expected := "H\tVN:Z:1\nS\t1\tACGT\tRC:i:5\nS\t2\tTTGA\tLN:i:4\tRC:i:4\r\nS\t3\tGG\tLN:i:2\n"
+	if output != expected {
+		t.Fatalf("edited lossless GFA not written as expected:\n%q", output)
+	}
+}
+
+// test that comments and a version added after reading are written
+func TestLosslessAdded(t *testing.T) {
+	myGFA := readLossless(t, []byte("H\tVN:Z:1.0\nS\t1\tACGT\n"))
+	myGFA.AddComment([]byte("added"))
+	if output := string(writeGFA(t, myGFA)); output != "H\tVN:Z:1.0\nS\t1\tACGT\n#\tadded\n" {
+		t.Fatalf("added comment not written:\n%q", output)
+	}
+	myGFA = readLossless(t, []byte("# no header\nS\t1\tACGT\n"))
+	if err := myGFA.AddVersion(1); err != nil {
+		t.Fatal(err)
+	}
+	if output := string(writeGFA(t, myGFA)); output != "H\tVN:Z:1\n# no header\nS\t1\tACGT\n" {
+		t.Fatalf("added version not written:\n%q", output)
+	}
+}
@@ -0,0 +1,367 @@
+package gfa
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// the value syntax for each optional field type, as given in the GFA spec
+var (
+	tagNameRegexp   = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]$`)
+	tagValueRegexps = map[byte]*regexp.Regexp{
+		'A': regexp.MustCompile(`^[!-~]$`),
+		'i': regexp.MustCompile(`^[-+]?[0-9]+$`),
+		'f': regexp.MustCompile(`^[-+]?[0-9]*\.?[0-9]+([eE][-+]?[0-9]+)?$`),
+		'Z': regexp.MustCompile(`^[ !-~]+$`),
+		'J': regexp.MustCompile(`^[ !-~]+$`),
+		'H': regexp.MustCompile(`^[0-9A-F]*$`),                                           // an empty byte array has no hex digits
+		'B': regexp.MustCompile(`^[cCsSiIf](,[-+]?[0-9]*\.?[0-9]+([eE][-+]?[0-9]+)?)*$`), // an empty array is just the subtype
+	}
+	// the range of values for each integer subtype of a B array
+	intArrayRanges = map[byte][2]int64{
+		'c': {math.MinInt8, math.MaxInt8},
+		'C': {0, math.MaxUint8},
+		's': {math.MinInt16, math.MaxInt16},
+		'S': {0, math.MaxUint16},
+		'i': {math.MinInt32, math.MaxInt32},
+		'I': {0, math.MaxUint32},
+	}
+)
+
+// An optionalField is a single TAG:TYPE:VALUE field, with the value held as it appears in the GFA line
+type optionalField struct {
+	tag   string
+	typ   byte
+	value string
+}
+
+// String returns the GFA formatted optional field
+func (oF *optionalField) String() string {
+	return fmt.Sprintf("%v:%c:%v", oF.tag, oF.typ, oF.value)
+}
+
+// OptionalFields holds the tags of a GFA line, in the order they were given
+type OptionalFields struct {
+	fields []*optionalField
+}
+
+// NewOptionalFields is an OptionalFields constructor, each field is given as TAG:TYPE:VALUE
+func NewOptionalFields(optional ...[]byte) (*OptionalFields, error) {
+	if len(optional) == 0 {
+		return nil, fmt.Errorf("No optional fields supplied")
+	}
+	oFs := new(OptionalFields)
+	for _, field := range optional {
+		if err := oFs.add(field); err != nil {
+			return nil, err
+		}
+	}
+	return oFs, nil
+}
+
+// add parses a single TAG:TYPE:VALUE field and adds it, a tag can only be added once (the value can only be empty for an empty byte array)
+func (oFs *OptionalFields) add(field []byte) error {
+	if len(field) < 5 || field[2] != ':' || field[4] != ':' {
+		return fmt.Errorf("Optional field must be formatted as TAG:TYPE:VALUE: %v", string(field))
+	}
+	tag, typ, value := string(field[:2]), field[3], string(field[5:])
+	if oFs.Has(tag) {
+		return fmt.Errorf("Duplicate optional field: %v", tag)
+	}
+	return oFs.set(tag, typ, value)
+}
+
+// clone returns a copy of a set of optional fields, so that they can be attached to another record
+func (oFs *OptionalFields) clone() *OptionalFields {
+	if oFs == nil {
+		return nil
+	}
+	newOFs := &OptionalFields{fields: make([]*optionalField, len(oFs.fields))}
+	for i, oF := range oFs.fields {
+		newField := *oF
+		newOFs.fields[i] = &newField
+	}
+	return newOFs
+}
+
+// String returns the tab separated GFA formatted optional fields
+func (oFs *OptionalFields) String() string {
+	if oFs == nil {
+		return ""
+	}
+	fields := make([]string, len(oFs.fields))
+	for i, oF := range oFs.fields {
+		fields[i] = oF.String()
+	}
+	return strings.Join(fields, "\t")
+}
+
+// appendOptionalFields adds the optional fields (if any) to a GFA formatted line
+func appendOptionalFields(line string, oFs *OptionalFields) string {
+	if oFs == nil || len(oFs.fields) == 0 {
+		return line
+	}
+	return fmt.Sprintf("%v\t%v", line, oFs.String())
+}
+
+// get returns the optional field for a tag, or nil if the tag is not present
+func (oFs *OptionalFields) get(tag string) *optionalField {
+	if oFs == nil {
+		return nil
+	}
+	for _, oF := range oFs.fields {
+		if oF.tag == tag {
+			return oF
+		}
+	}
+	return nil
+}
+
+// getTyped returns the value for a tag, checking that the tag is present and of the expected type
+func (oFs *OptionalFields) getTyped(tag string, typ byte) (string, error) {
+	oF := oFs.get(tag)
+	if oF == nil {
+		return "", fmt.Errorf("Optional field not present: %v", tag)
+	}
+	if oF.typ != typ {
+		return "", fmt.Errorf("Optional field %v has type %c, not %c", tag, oF.typ, typ)
+	}
+	return oF.value, nil
+}
+
+// set validates a tag, type and value and then adds it, replacing the value of any existing field with the same tag
+func (oFs *OptionalFields) set(tag string, typ byte, value string) error {
+	if oFs == nil {
+		return fmt.Errorf("Can't set optional field %v, the record has no optional fields (e.g. a comment)", tag)
+	}
+	if !tagNameRegexp.MatchString(tag) {
+		return fmt.Errorf("Optional field tag must be a letter followed by a letter or digit: %v", tag)
+	}
+	valueRegexp, ok := tagValueRegexps[typ]
+	if !ok {
+		return fmt.Errorf("Optional field %v has unknown type: %c", tag, typ)
+	}
+	if !valueRegexp.MatchString(value) {
+		return fmt.Errorf("Optional field %v has a bad value for type %c: %v", tag, typ, value)
+	}
+	if typ == 'B' {
+		if err := checkArray(value); err != nil {
+			return fmt.Errorf("Optional field %v has a bad array: %v", tag, err)
+		}
+	}
+	if oF := oFs.get(tag); oF != nil {
+		oF.typ, oF.value = typ, value
+		return nil
+	}
+	oFs.fields = append(oFs.fields, &optionalField{tag: tag, typ: typ, value: value})
+	return nil
+}
+
+// checkArray checks that the values of a B array are within the range of the array subtype
+func checkArray(value string) error {
+	values := strings.Split(value, ",")
+	if values[0] == "f" {
+		for _, v := range values[1:] {
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	limits := intArrayRanges[values[0][0]]
+	for _, v := range values[1:] {
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || i < limits[0] || i > limits[1] {
+			return fmt.Errorf("value %v out of range for subtype %v", v, values[0])
+		}
+	}
+	return nil
+}
+
+// Has checks if a tag is present
+func (oFs *OptionalFields) Has(tag string) bool {
+	return oFs.get(tag) != nil
+}
+
+// Tags returns the tags in the order they are held
+func (oFs *OptionalFields) Tags() []string {
+	tags := []string{}
+	if oFs == nil {
+		return tags
+	}
+	for _, oF := range oFs.fields {
+		tags = append(tags, oF.tag)
+	}
+	return tags
+}
+
+// GetType returns the type of a tag (A/i/f/Z/J/H/B)
+func (oFs *OptionalFields) GetType(tag string) (byte, error) {
+	oF := oFs.get(tag)
+	if oF == nil {
+		return 0, fmt.Errorf("Optional field not present: %v", tag)
+	}
+	return oF.typ, nil
+}
+
+// Remove deletes a tag, returning false if it was not present
+func (oFs *OptionalFields) Remove(tag string) bool {
+	if oFs == nil {
+		return false
+	}
+	for i, oF := range oFs.fields {
+		if oF.tag == tag {
+			oFs.fields = append(oFs.fields[:i], oFs.fields[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// GetChar returns the value of a printable character (A) tag
+func (oFs *OptionalFields) GetChar(tag string) (byte, error) {
+	value, err := oFs.getTyped(tag, 'A')
+	if err != nil {
+		return 0, err
+	}
+	return value[0], nil
+}
+
+// SetChar sets a printable character (A) tag
+func (oFs *OptionalFields) SetChar(tag string, value byte) error {
+	return oFs.set(tag, 'A', string(value))
+}
+
+// GetInt returns the value of an integer (i) tag
+func (oFs *OptionalFields) GetInt(tag string) (int, error) {
+	value, err := oFs.getTyped(tag, 'i')
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimPrefix(value, "+"))
+}
+
+// SetInt sets an integer (i) tag
+func (oFs *OptionalFields) SetInt(tag string, value int) error {
+	return oFs.set(tag, 'i', strconv.Itoa(value))
+}
+
+// GetFloat returns the value of a float (f) tag
+func (oFs *OptionalFields) GetFloat(tag string) (float64, error) {
+	value, err := oFs.getTyped(tag, 'f')
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// SetFloat sets a float (f) tag
+func (oFs *OptionalFields) SetFloat(tag string, value float64) error {
+	return oFs.set(tag, 'f', strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// GetString returns the value of a string (Z) tag
+func (oFs *OptionalFields) GetString(tag string) (string, error) {
+	return oFs.getTyped(tag, 'Z')
+}
+
+// SetString sets a string (Z) tag
+func (oFs *OptionalFields) SetString(tag string, value string) error {
+	return oFs.set(tag, 'Z', value)
+}
+
+// GetJSON unmarshals the value of a JSON (J) tag into v
+func (oFs *OptionalFields) GetJSON(tag string, v interface{}) error {
+	value, err := oFs.getTyped(tag, 'J')
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(value), v)
+}
+
+// SetJSON sets a JSON (J) tag, using the JSON encoding of v
+func (oFs *OptionalFields) SetJSON(tag string, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return oFs.set(tag, 'J', string(value))
+}
+
+// GetByteArray returns the decoded value of a hex byte array (H) tag
+func (oFs *OptionalFields) GetByteArray(tag string) ([]byte, error) {
+	value, err := oFs.getTyped(tag, 'H')
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(value)
+}
+
+// SetByteArray sets a hex byte array (H) tag
+func (oFs *OptionalFields) SetByteArray(tag string, value []byte) error {
+	return oFs.set(tag, 'H', strings.ToUpper(hex.EncodeToString(value)))
+}
+
+// GetIntArray returns the values of an integer array (B with subtype c/C/s/S/i/I) tag
+func (oFs *OptionalFields) GetIntArray(tag string) ([]int, error) {
+	value, err := oFs.getTyped(tag, 'B')
+	if err != nil {
+		return nil, err
+	}
+	values := strings.Split(value, ",")
+	if values[0] == "f" {
+		return nil, fmt.Errorf("Optional field %v is a float array", tag)
+	}
+	ints := make([]int, len(values)-1)
+	for i, v := range values[1:] {
+		if ints[i], err = strconv.Atoi(strings.TrimPrefix(v, "+")); err != nil {
+			return nil, err
+		}
+	}
+	return ints, nil
+}
+
+// SetIntArray sets an integer array (B) tag, the subtype (c/C/s/S/i/I) gives the size and sign of the integers
+func (oFs *OptionalFields) SetIntArray(tag string, subtype byte, values []int) error {
+	if _, ok := intArrayRanges[subtype]; !ok {
+		return fmt.Errorf("Integer array subtype must be one of cCsSiI: %c", subtype)
+	}
+	fields := []string{string(subtype)}
+	for _, v := range values {
+		fields = append(fields, strconv.Itoa(v))
+	}
+	return oFs.set(tag, 'B', strings.Join(fields, ","))
+}
+
+// GetFloatArray returns the values of a float array (B with subtype f) tag
+func (oFs *OptionalFields) GetFloatArray(tag string) ([]float64, error) {
+	value, err := oFs.getTyped(tag, 'B')
+	if err != nil {
+		return nil, err
+	}
+	values := strings.Split(value, ",")
+	if values[0] != "f" {
+		return nil, fmt.Errorf("Optional field %v is an integer array", tag)
+	}
+	floats := make([]float64, len(values)-1)
+	for i, v := range values[1:] {
+		if floats[i], err = strconv.ParseFloat(v, 64); err != nil {
+			return nil, err
+		}
+	}
+	return floats, nil
+}
+
+// SetFloatArray sets a float array (B with subtype f) tag
+func (oFs *OptionalFields) SetFloatArray(tag string, values []float64) error {
+	fields := []string{"f"}
+	for _, v := range values {
+		fields = append(fields, strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	return oFs.set(tag, 'B', strings.Join(fields, ","))
+}
@@ -0,0 +1,210 @@
+package gfa
+
+import (
+	"testing"
+)
+
+// parse optional fields of every type and check their order is preserved
+func TestNewOptionalFields(t *testing.T) {
+	oFs, err := NewOptionalFields([]byte("RC:i:12"), []byte("XA:A:c"), []byte("XF:f:-1.5e3"), []byte("UR:Z:http://example.com/seq.fa"), []byte("XJ:J:{\"a\":[1,2]}"), []byte("SH:H:0AFF"), []byte("XB:B:c,-1,2,3"), []byte("XY:B:f,1.5,2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(oFs.String())
+	if oFs.String() != "RC:i:12\tXA:A:c\tXF:f:-1.5e3\tUR:Z:http://example.com/seq.fa\tXJ:J:{\"a\":[1,2]}\tSH:H:0AFF\tXB:B:c,-1,2,3\tXY:B:f,1.5,2" {
+		t.Fatal("optional fields were not kept in order")
+	}
+	if rc, err := oFs.GetInt("RC"); err != nil || rc != 12 {
+		t.Fatal("could not get integer tag")
+	}
+	if xa, err := oFs.GetChar("XA"); err != nil || xa != 'c' {
+		t.Fatal("could not get character tag")
+	}
+	if xf, err := oFs.GetFloat("XF"); err != nil || xf != -1500 {
+		t.Fatal("could not get float tag")
+	}
+	if ur, err := oFs.GetString("UR"); err != nil || ur != "http://example.com/seq.fa" {
+		t.Fatal("could not get string tag containing colons")
+	}
+	xj := make(map[string][]int)
+	if err := oFs.GetJSON("XJ", &xj); err != nil || len(xj["a"]) != 2 {
+		t.Fatal("could not get JSON tag")
+	}
+	if sh, err := oFs.GetByteArray("SH"); err != nil || len(sh) != 2 || sh[1] != 255 {
+		t.Fatal("could not get byte array tag")
+	}
+	if xb, err := oFs.GetIntArray("XB"); err != nil || len(xb) != 3 || xb[0] != -1 {
+		t.Fatal("could not get integer array tag")
+	}
+	if xy, err := oFs.GetFloatArray("XY"); err != nil || len(xy) != 2 || xy[0] != 1.5 {
+		t.Fatal("could not get float array tag")
+	}
+	t.Log("checking type mismatches and missing tags give errors:")
+	if _, err := oFs.GetInt("XF"); err != nil {
+		t.Log(err)
+	} else {
+		t.Fatal("got an integer from a float tag")
+	}
+	if _, err := oFs.GetFloatArray("XB"); err != nil {
+		t.Log(err)
+	} else {
+		t.Fatal("got a float array from an integer array tag")
+	}
+	if _, err := oFs.GetString("NO"); err != nil {
+		t.Log(err)
+	} else {
+		t.Fatal("got a missing tag")
+	}
+}
+
+// check that badly formatted optional fields are rejected
+func TestBadOptionalFields(t *testing.T) {
+	for _, field := range []string{"RC:i:1.5", "R:i:1", "1C:i:1", "RC:q:1", "XB:B:c,1000", "XB:B:q,1", "SH:H:0G", "RC:i", "XA:A:ab"} {
+		if _, err := NewOptionalFields([]byte(field)); err != nil {
+			t.Log(err)
+		} else {
+			t.Fatalf("bad optional field was accepted: %v", field)
+		}
+	}
+	if _, err := NewOptionalFields([]byte("RC:i:1"), []byte("RC:i:2")); err != nil {
+		t.Log(err)
+	} else {
+		t.Fatal("duplicate optional field was accepted")
+	}
+}
+
+// set, replace and remove optional fields
+func TestSetOptionalFields(t *testing.T) {
+	oFs, err := NewOptionalFields([]byte("LN:i:4"), []byte("KC:i:10"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := oFs.SetInt("KC", 20); err != nil {
+		t.Fatal(err)
+	}
+	if err := oFs.SetFloatArray("XY", []float64{0.5, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := oFs.SetIntArray("XB", 'C', []int{1, 255}); err != nil {
+		t.Fatal(err)
+	}
+	if err := oFs.SetByteArray("SH", []byte{10, 255}); err != nil {
+		t.Fatal(err)
+	}
+	if err := oFs.SetJSON("XJ", map[string]int{"a": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := oFs.SetChar("XA", 'z'); err != nil {
+		t.Fatal(err)
+	}
+	if err := oFs.SetFloat("XF", 0.25); err != nil {
+		t.Fatal(err)
+	}
+	if !oFs.Remove("LN") || oFs.Remove("LN") {
+		t.Fatal("could not remove tag")
+	}
+	t.Log(oFs.String())
+	if oFs.String() != "KC:i:20\tXY:B:f,0.5,2\tXB:B:C,1,255\tSH:H:0AFF\tXJ:J:{\"a\":1}\tXA:A:z\tXF:f:0.25" {
+		t.Fatal("optional fields were not set correctly")
+	}
+	if typ, err := oFs.GetType("XB"); err != nil || typ != 'B' {
+		t.Fatal("could not get tag type")
+	}
+	t.Log("checking bad values can't be set:")
+	if err := oFs.SetIntArray("XB", 'c', []int{200}); err != nil {
+		t.Log(err)
+	} else {
+		t.Fatal("out of range array value was set")
+	}
+	if err := oFs.SetString("X", "abc"); err != nil {
+		t.Log(err)
+	} else {
+		t.Fatal("bad tag name was set")
+	}
+	if err := oFs.SetString("XZ", "a\tb"); err != nil {
+		t.Log(err)
+	} else {
+		t.Fatal("string with a tab was set")
+	}
+}
+
+// check that empty arrays can be set and read back from a GFA line
+func TestEmptyArrayOptionalFields(t *testing.T) {
+	seg, err := NewSegment([]byte("1"), []byte("ACGT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	oFs := seg.GetOptionalFields()
+	if err := oFs.SetIntArray("XB", 'i', []int{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := oFs.SetFloatArray("XY", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := oFs.SetByteArray("SH", []byte{}); err != nil {
+		t.Fatal(err)
+	}
+	line := seg.PrintGFAline()
+	if line != "S\t1\tACGT\tLN:i:4\tXB:B:i\tXY:B:f\tSH:H:" {
+		t.Fatalf("unexpected line with empty arrays: %v", line)
+	}
+	myGFA := readTestGFA(t, "H\tVN:Z:1\n"+line+"\n")
+	seg, _ = myGFA.GetSegment([]byte("1"))
+	ints, err := seg.GetOptionalFields().GetIntArray("XB")
+	if err != nil || len(ints) != 0 {
+		t.Fatalf("empty integer array was not read back: %v %v", ints, err)
+	}
+	floats, err := seg.GetOptionalFields().GetFloatArray("XY")
+	if err != nil || len(floats) != 0 {
+		t.Fatalf("empty float array was not read back: %v %v", floats, err)
+	}
+	bytes, err := seg.GetOptionalFields().GetByteArray("SH")
+	if err != nil || len(bytes) != 0 {
+		t.Fatalf("empty byte array was not read back: %v %v", bytes, err)
+	}
+	if seg.PrintGFAline() != line {
+		t.Fatalf("empty arrays were not written back: %v", seg.PrintGFAline())
+	}
+}
+
+// check that segments keep their tags, including the length tag
+func TestSegmentOptionalFields(t *testing.T) {
+	seg, err := NewSegment([]byte("1"), []byte("ACTG"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	oFs, err := NewOptionalFields([]byte("KC:i:30"), []byte("LN:i:4"), []byte("XX:Z:unknown tag"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seg.AddOptionalFields(oFs)
+	if seg.PrintGFAline() != "S\t1\tACTG\tKC:i:30\tLN:i:4\tXX:Z:unknown tag" {
+		t.Fatalf("segment tags not preserved: %v", seg.PrintGFAline())
+	}
+	if kc, err := seg.GetKmerCount(); err != nil || kc != 30 {
+		t.Fatal("could not get k-mer count")
+	}
+	if !seg.GetOptionalFields().Has("XX") {
+		t.Fatal("could not get segment optional fields")
+	}
+}
+
+// check that tags can be set on records that were read without any
+func TestSetOptionalFieldsOnUntaggedRecord(t *testing.T) {
+	myGFA := readTestGFA(t, "H\tVN:Z:1\nS\t1\tACGT\nL\t1\t+\t1\t-\t0M\n")
+	seg, _ := myGFA.GetSegment([]byte("1"))
+	if err := seg.GetOptionalFields().SetInt("RC", 12); err != nil {
+		t.Fatal(err)
+	}
+	if seg.PrintGFAline() != "S\t1\tACGT\tLN:i:4\tRC:i:12" {
+		t.Fatalf("tag not added to segment: %v", seg.PrintGFAline())
+	}
+	links, _ := myGFA.GetLinks()
+	if err := links[0].GetOptionalFields().SetString("ID", "e1"); err != nil || links[0].PrintGFAline() != "L\t1\t+\t1\t-\t0M\tID:Z:e1" {
+		t.Fatalf("tag not added to link: %v", links[0].PrintGFAline())
+	}
+	// comments have no optional fields, so setting a tag is an error rather than a panic
+	if err := newComment([]byte("a comment")).GetOptionalFields().SetInt("RC", 1); err == nil {
+		t.Fatal("set a tag on a comment")
+	}
+}
@@ -0,0 +1,197 @@
+package gfa
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// parallelChunkSize is the approximate number of bytes in each chunk of lines handed to a parsing goroutine
+var parallelChunkSize = 1 << 20
+
+// a chunk is a run of whole lines from the input, numbered so that the parsed chunks can be put back in order
+type chunk struct {
+	index     int
+	firstLine int // the line number of the first line in the chunk
+	data      []byte
+}
+
+// a parsedLine holds the result of parsing a single line
+type parsedLine struct {
+	raw   []byte // the line as read, including its line ending
+	blank bool
+	line  Record
+	perr  *ParseError
+}
+
+// a parsedChunk holds the parsed lines of a chunk
+type parsedChunk struct {
+	index int
+	lines []parsedLine
+}
+
+/*
+ReadAll reads the remaining lines from the reader and adds them to the GFA instance held by the reader, which is returned
+
+// the lines are split into chunks which are parsed by the given number of goroutines (the number of CPUs if workers < 1),
+the records are then added to the GFA instance in their original order, so the result is the same as calling Read and Add on every line
+
+// if the GFA version is not known from the header lines read by NewReader, the lines are read sequentially
+*/
+func (r *Reader) ReadAll(workers int) (*GFA, error) {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	// the version decides how lines are parsed, so parsing can only be done in parallel once it is known
+	if workers == 1 || r.gfa.GetVersion() == 0 {
+		// lines are interned straight into the name table of the GFA instance, so adding them doesn't need to move their names,
+		// the names of a line that is skipped or can't be added are taken back out
+		for {
+			n := r.gfa.names.len()
+			line, err := r.readNext(r.gfa.names)
+			if err == io.EOF {
+				return r.gfa, nil
+			}
+			if err == nil && line != nil {
+				err = line.Add(r.gfa)
+			}
+			if err != nil || line == nil {
+				r.gfa.names.truncate(n)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	version := r.gfa.GetVersion()
+	done := make(chan struct{})
+	defer close(done)
+	chunks := make(chan chunk, workers)
+	results := make(chan parsedChunk, workers)
+	readErr := make(chan error, 1)
+	// a chunk holds a slot from when it is read until its lines are added, so a slow chunk can't leave an unlimited number of
+	// parsed chunks waiting behind it
+	slots := make(chan struct{}, workers*2)
+
+	// split the input into chunks of whole lines
+	go func() {
+		defer close(chunks)
+		lineNum := r.lineNum + 1
+		for index := 0; ; index++ {
+			data := make([]byte, parallelChunkSize)
+			n, err := io.ReadFull(r.reader, data)
+			data = data[:n]
+			if err == nil {
+				// finish the chunk at the end of a line
+				var rest []byte
+				rest, err = r.reader.ReadBytes('\n')
+				data = append(data, rest...)
+			}
+			if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+				readErr <- err
+				return
+			}
+			if len(data) != 0 {
+				select {
+				case slots <- struct{}{}:
+				case <-done:
+					return
+				}
+				select {
+				case chunks <- chunk{index: index, firstLine: lineNum, data: data}:
+				case <-done:
+					return
+				}
+				lineNum += bytes.Count(data, []byte("\n"))
+				if data[len(data)-1] != '\n' {
+					lineNum++
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	// parse the chunks
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range chunks {
+				result := parsedChunk{index: c.index}
+				// each chunk has its own name table, which is moved onto the name table of the GFA instance as its lines are added
+				names := newNameTable()
+				lineNum := c.firstLine
+				for len(c.data) != 0 {
+					end := bytes.IndexByte(c.data, '\n') + 1
+					if end == 0 {
+						end = len(c.data)
+					}
+					raw := c.data[:end]
+					c.data = c.data[end:]
+					bytesLine := bytes.TrimSuffix(bytes.TrimSuffix(raw, []byte("\n")), []byte("\r"))
+					pl := parsedLine{raw: raw, blank: len(bytesLine) == 0}
+					pl.line, pl.perr = parseLine(bytesLine, lineNum, version, names)
+					result.lines = append(result.lines, pl)
+					lineNum++
+				}
+				select {
+				case results <- result:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// add the parsed lines to the GFA instance in the order they were read
+	waiting := make(map[int]parsedChunk)
+	next := 0
+	for result := range results {
+		waiting[result.index] = result
+		for {
+			pc, ok := waiting[next]
+			if !ok {
+				break
+			}
+			delete(waiting, next)
+			next++
+			<-slots
+			for _, pl := range pc.lines {
+				r.lineNum++
+				// blank lines are kept in lossless mode, so that they can be written back ahead of the next line
+				if pl.blank && r.lossless {
+					r.pending = append(r.pending, pl.raw...)
+					continue
+				}
+				line, err := r.accept(pl.line, pl.perr, pl.raw)
+				if err != nil {
+					return nil, err
+				}
+				if line == nil {
+					continue
+				}
+				if err := line.Add(r.gfa); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	select {
+	case err := <-readErr:
+		return nil, err
+	default:
+	}
+	if len(r.pending) != 0 {
+		r.gfa.trailer = append(r.gfa.trailer, r.pending...)
+		r.pending = nil
+	}
+	return r.gfa, nil
+}
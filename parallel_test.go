@@ -0,0 +1,106 @@
+package gfa
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// test that reading in parallel gives the same GFA instance as reading sequentially
+func TestReadAll(t *testing.T) {
+	defer func(size int) { parallelChunkSize = size }(parallelChunkSize)
+	parallelChunkSize = 100
+	input, err := ioutil.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sequential := writeGFA(t, readTestGFA(t, string(input)))
+	for _, workers := range []int{0, 1, 4} {
+		reader, err := NewReader(bytes.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		myGFA, err := reader.ReadAll(workers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(writeGFA(t, myGFA), sequential) {
+			t.Fatalf("reading with %d workers did not give the same GFA as reading sequentially", workers)
+		}
+	}
+	// a lossless read in parallel must still write back the original bytes
+	reader, err := NewReader(bytes.NewReader(input), Lossless())
+	if err != nil {
+		t.Fatal(err)
+	}
+	myGFA, err := reader.ReadAll(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(writeGFA(t, myGFA), input) {
+		t.Fatal("lossless parallel read did not write back the original bytes")
+	}
+}
+
+// test that errors are reported for the same lines as a sequential read
+func TestReadAllErrors(t *testing.T) {
+	defer func(size int) { parallelChunkSize = size }(parallelChunkSize)
+	parallelChunkSize = 10
+	input := "H\tVN:Z:1\nS\t1\tACGT\nX\tfoo\nS\t2\tTTGA\n\nL\t1\t+\t2\nL\t1\t+\t2\t+\t0M"
+	reader, err := NewReader(strings.NewReader(input), Lenient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	myGFA, err := reader.ReadAll(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(myGFA.segments) != 2 || len(myGFA.links) != 1 {
+		t.Fatalf("expected 2 segments and 1 link, got %d and %d", len(myGFA.segments), len(myGFA.links))
+	}
+	lines := []int{}
+	for _, perr := range reader.Errors() {
+		lines = append(lines, perr.Line)
+	}
+	if !reflect.DeepEqual(lines, []int{3, 5, 6}) {
+		t.Fatalf("errors reported for the wrong lines: %v", lines)
+	}
+	reader, err = NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = reader.ReadAll(3)
+	if perr, ok := err.(*ParseError); !ok || perr.Line != 3 {
+		t.Fatalf("expected a ParseError for line 3, got %v", err)
+	}
+}
+
+// test that GFA2 records don't hold on to the line they were parsed from, which would keep every chunk of a parallel read alive
+func TestGFA2RecordsCopyFields(t *testing.T) {
+	line := []byte("E\te1\t1+\t2+\t3\t8$\t0\t5\t5M\nF\t2\tread1+\t0\t10$\t20\t30\t*\nG\tg1\t1+\t3-\t250\t*\nO\tp1\t1+ 2+\nU\tu1\t1 3 e1")
+	records := []Record{}
+	for i, bytesLine := range bytes.Split(line, []byte("\n")) {
+		record, perr := parseLine(bytesLine, i+1, 2, newNameTable())
+		if perr != nil {
+			t.Fatal(perr)
+		}
+		records = append(records, record)
+	}
+	expected := []string{}
+	for _, record := range records {
+		expected = append(expected, record.PrintGFAline())
+	}
+	// overwrite the line, leaving the tabs and newlines in place
+	for i, b := range line {
+		if b != '\t' && b != '\n' && b != ' ' {
+			line[i] = 'x'
+		}
+	}
+	for i, record := range records {
+		if record.PrintGFAline() != expected[i] {
+			t.Fatalf("record changed with the line it was parsed from: %v", record.PrintGFAline())
+		}
+	}
+}
@@ -3,28 +3,88 @@ package gfa
 import (
 	"bufio"
 	"bytes"
-	"fmt"
 	"io"
+	"os"
 )
 
 // Reader implements GFA format reading.
 type Reader struct {
-	reader *bufio.Reader
-	gfa    *GFA
+	reader      *bufio.Reader
+	gfa         *GFA
+	lossless    bool
+	lenient     bool
+	foldCase    bool
+	lineNum     int           // the number of lines read so far
+	pending     []byte        // blank or skipped lines waiting to be attached to the next line (lossless mode only)
+	errs        []*ParseError // the errors for lines skipped in lenient mode
+	preamble    []Record      // the header and comment lines read by NewReader, kept for Stream
+	compression Compression
+	closers     []io.Closer // the file (and decompressor) opened by Open
+	file        *os.File    // the file opened by Open, used to fetch indexed records
+	index       *Index
+}
+
+// ReaderOption is a function that sets an option on a Reader
+type ReaderOption func(*Reader)
+
+// Lossless sets a Reader to keep the original bytes and order of every line, so that an unmodified GFA can be written back byte-for-byte
+func Lossless() ReaderOption {
+	return func(r *Reader) {
+		r.lossless = true
+	}
+}
+
+// Lenient sets a Reader to skip malformed lines (such as blank lines and unknown record types) instead of stopping,
+// the errors for the skipped lines are available from the Errors method
+func Lenient() ReaderOption {
+	return func(r *Reader) {
+		r.lenient = true
+	}
+}
+
+// CaseInsensitive sets a Reader to store segment sequences in upper case, so that soft-masked (lower case) bases are packed into 2 bits
+// instead of being held as exceptions, the case of the sequences is lost (apart from lines written back unmodified in lossless mode)
+func CaseInsensitive() ReaderOption {
+	return func(r *Reader) {
+		r.foldCase = true
+	}
+}
+
+// the number of fields (including the record type) that each record type requires
+var requiredFields = map[string]int{
+	"H": 1,
+	"S": 3, // GFA2 segments have an extra length field
+	"L": 6,
+	"C": 7,
+	"P": 4,
+	"W": 7,
+	"J": 6,
+	"E": 9,
+	"F": 8,
+	"G": 6,
+	"O": 3,
+	"U": 3,
 }
 
 // NewReader returns a new Reader, reading from the given io.Reader
-func NewReader(r io.Reader) (*Reader, error) {
+func NewReader(r io.Reader, opts ...ReaderOption) (*Reader, error) {
 	gfaReader := &Reader{
 		reader: bufio.NewReader(r),
 		gfa:    NewGFA(),
 	}
+	for _, opt := range opts {
+		opt(gfaReader)
+	}
+	if gfaReader.lossless {
+		gfaReader.gfa.lossless = true
+		gfaReader.gfa.raw = make(map[Record]*rawLine)
+	}
 	// check there is something in the file
 	_, err := gfaReader.reader.Peek(1)
 	if err != nil {
 		return nil, err
 	}
-	// get the header lines, ignore comments and stop looking once a non header/comment line encountered
+	// get the header lines and comments, stop looking once a non header/comment line encountered
 	for {
 		peek, err := gfaReader.reader.Peek(1)
 		if err == io.EOF {
@@ -34,28 +94,20 @@ func NewReader(r io.Reader) (*Reader, error) {
 			return nil, err
 		}
 		// only look at lines beginning with H (72) and # (35)
-		if (peek[0] == 72) || (peek[0] == 35) {
-			line, err := gfaReader.reader.ReadBytes('\n')
-			if err != nil {
-				return nil, io.ErrUnexpectedEOF
-			}
-			if bytes.Contains(line, []byte("VN:Z:1")) {
-				err := gfaReader.gfa.AddVersion(1)
-				if err != nil {
-					return nil, err
-				}
-			} else if bytes.Contains(line, []byte("VN:Z:2")) {
-				err := gfaReader.gfa.AddVersion(2)
-				if err != nil {
-					return nil, err
-				}
-			}
-			if line[0] == '#' {
-				gfaReader.gfa.AddComment(line[1 : len(line)-1])
-			}
-		} else {
+		if (peek[0] != 72) && (peek[0] != 35) {
 			break
 		}
+		line, err := gfaReader.readNext(newNameTable())
+		if err != nil {
+			return nil, err
+		}
+		if line == nil {
+			continue
+		}
+		if err := line.Add(gfaReader.gfa); err != nil {
+			return nil, err
+		}
+		gfaReader.preamble = append(gfaReader.preamble, line)
 	}
 	return gfaReader, nil
 }
@@ -65,66 +117,218 @@ func (r *Reader) CollectGFA() *GFA {
 	return r.gfa
 }
 
-// Read returns the next (non H/#) GFA line from the reader
-func (r *Reader) Read() (gfaLine, error) {
-	bytesLine, err := r.reader.ReadBytes('\n')
+// Errors returns the errors for any lines that have been skipped by a Reader in lenient mode
+func (r *Reader) Errors() []*ParseError {
+	return r.errs
+}
+
+// readLine returns the next line from the reader, both as it was read and with the line ending removed
+func (r *Reader) readLine() ([]byte, []byte, error) {
+	raw, err := r.reader.ReadBytes('\n')
+	if err != nil && (err != io.EOF || len(raw) == 0) {
+		return nil, nil, err
+	}
+	r.lineNum++
+	line := bytes.TrimSuffix(raw, []byte("\n"))
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	return raw, line, nil
+}
+
+// Read returns the next GFA line from the reader (headers and comments found after the first record are also returned)
+//
+// a line that can't be parsed is returned as a *ParseError, unless the Reader is in lenient mode, in which case it is skipped
+func (r *Reader) Read() (Record, error) {
+	for {
+		line, err := r.readNext(newNameTable())
+		if err != nil || line != nil {
+			return line, err
+		}
+	}
+}
+
+// readNext reads and parses the next line from the reader, interning its segment names in the given name table,
+// a nil Record is returned if the line was skipped
+func (r *Reader) readNext(names *nameTable) (Record, error) {
+	raw, bytesLine, err := r.readLine()
 	if err != nil {
+		if err == io.EOF && len(r.pending) != 0 {
+			r.gfa.trailer = append(r.gfa.trailer, r.pending...)
+			r.pending = nil
+		}
 		return nil, err
 	}
-	// ignore empty lines TODO: I need a better fix than this
-	if len(bytesLine) <= 1 {
-		return nil, fmt.Errorf("Encountered empty line, quiting")
+	// blank lines are kept in lossless mode, so that they can be written back ahead of the next line
+	if len(bytesLine) == 0 && r.lossless {
+		r.pending = append(r.pending, raw...)
+		return nil, nil
+	}
+	line, perr := parseLine(bytesLine, r.lineNum, r.gfa.GetVersion(), names)
+	return r.accept(line, perr, raw)
+}
+
+// accept decides what to do with a parsed line: a line that failed to parse is either returned as an error or (in lenient mode) skipped,
+// and in lossless mode the original bytes are kept
+func (r *Reader) accept(line Record, perr *ParseError, raw []byte) (Record, error) {
+	if perr != nil {
+		if !r.lenient {
+			return nil, perr
+		}
+		r.errs = append(r.errs, perr)
+		if r.lossless {
+			r.pending = append(r.pending, raw...)
+		}
+		return nil, nil
+	}
+	if seg, ok := line.(*Segment); ok && r.foldCase {
+		seg.seq = seg.seq.foldCase()
 	}
-	// trim the line
-	bytesLine = bytesLine[:len(bytesLine)-1]
-	if bytesLine[len(bytesLine)-1] == '\r' {
-		bytesLine = bytesLine[:len(bytesLine)-1]
+	if r.lossless {
+		r.gfa.keepRaw(line, r.pending, raw)
+		r.pending = nil
 	}
-	var line gfaLine
-	// split the line on tab
+	return line, nil
+}
+
+// newParseError creates a ParseError, using the field number attached to the error if there is one
+func newParseError(lineNum int, recordType string, field int, err error) *ParseError {
+	if fe, ok := err.(*fieldError); ok {
+		field, err = fe.field, fe.err
+	}
+	return &ParseError{Line: lineNum, RecordType: recordType, Field: field, Err: err}
+}
+
+// parseLine creates a Record from a single line of a GFA file (without the line ending), the line number is used for any error
+// and the GFA version decides how version specific records are handled, segment names are interned in the given name table
+func parseLine(bytesLine []byte, lineNum, version int, names *nameTable) (Record, *ParseError) {
+	if len(bytesLine) == 0 {
+		return nil, newParseError(lineNum, "", -1, ErrEmptyLine)
+	}
+	// comment lines are kept as they are
+	if bytesLine[0] == '#' {
+		return newComment(bytesLine[1:]), nil
+	}
+	// split the line on tab, ignoring any trailing tabs
 	fields := bytes.Split(bytesLine, []byte("\t"))
-	if len(fields) < 3 {
-		return nil, fmt.Errorf("Not enough fields in GFA line: %v", string(bytesLine))
+	for len(fields) > 1 && len(fields[len(fields)-1]) == 0 {
+		fields = fields[:len(fields)-1]
+	}
+	recordType := string(fields[0])
+	required, ok := requiredFields[recordType]
+	if !ok {
+		return nil, newParseError(lineNum, recordType, 0, ErrUnknownRecordType)
 	}
-	// determine what type of line it is and then create a gfaLine using the required fields
-	switch bytesLine[0] {
+	// GFA1 and GFA2 share the segment and header lines but the remaining record types are version specific
+	switch recordType {
+	case "L", "C", "P", "W", "J":
+		if version == 2 {
+			return nil, newParseError(lineNum, recordType, 0, ErrWrongVersion)
+		}
+	case "E", "F", "G", "O", "U":
+		if version == 1 {
+			return nil, newParseError(lineNum, recordType, 0, ErrWrongVersion)
+		}
+	case "S":
+		if version == 2 {
+			required++
+		}
+	}
+	if len(fields) < required {
+		return nil, newParseError(lineNum, recordType, len(fields), ErrTooFewFields)
+	}
+	var line Record
+	var err error
+	// determine what type of line it is and then create a Record using the required fields
+	switch recordType {
 	// segment line (S)
-	case 83:
-		line, err = NewSegment(fields[1], fields[2])
+	case "S":
+		if version == 2 {
+			line, err = newGFA2Segment(names, fields[1], fields[2], fields[3])
+			if err != nil {
+				return nil, newParseError(lineNum, recordType, -1, err)
+			}
+			break
+		}
+		line, err = newSegment(names, fields[1], fields[2])
 		if err != nil {
-			return nil, fmt.Errorf("Could not read segment line: %v", err)
+			return nil, newParseError(lineNum, recordType, -1, err)
 		}
-		fields = fields[3:]
 	// link line (L)
-	case 76:
-		line, err = NewLink(fields[1], fields[2], fields[3], fields[4], fields[5])
+	case "L":
+		line, err = newLink(names, fields[1], fields[2], fields[3], fields[4], fields[5])
 		if err != nil {
-			return nil, fmt.Errorf("Could not read link line: %v", err)
+			return nil, newParseError(lineNum, recordType, -1, err)
 		}
-		fields = fields[6:]
 	// containment line (C)
-	case 67:
-		line, err = NewSegment([]byte("dummy4containment"), []byte("actg"))
+	case "C":
+		line, err = newContainment(names, fields[1], fields[2], fields[3], fields[4], fields[5], fields[6])
 		if err != nil {
-			return nil, fmt.Errorf("Could not read containment line: %v", err)
+			return nil, newParseError(lineNum, recordType, -1, err)
 		}
-		fields = fields[3:]
 	// path line (P)
-	case 80:
-		line, err = NewPath(fields[1], bytes.Split(fields[2], []byte(",")), bytes.Split(fields[3], []byte(",")))
+	case "P":
+		line, err = newPath(names, fields[1], bytes.Split(fields[2], []byte(",")), bytes.Split(fields[3], []byte(",")))
 		if err != nil {
-			return nil, fmt.Errorf("Could not read path line: %v", err)
+			return nil, newParseError(lineNum, recordType, -1, err)
 		}
-		fields = fields[4:]
-	default:
-		return nil, fmt.Errorf("Encountered unknown line type: %v", string(bytesLine[0]))
-	}
-	// fields slice will now only contain optional fields (if present)
-	if len(fields) != 0 {
-		oFs, err := NewOptionalFields(fields...)
+	// walk line (W)
+	case "W":
+		line, err = newWalk(names, fields[1], fields[2], fields[3], fields[4], fields[5], fields[6])
 		if err != nil {
-			return nil, fmt.Errorf("Could not parse optional fields: %v", err)
+			return nil, newParseError(lineNum, recordType, -1, err)
 		}
+	// jump line (J)
+	case "J":
+		line, err = newJump(names, fields[1], fields[2], fields[3], fields[4], fields[5])
+		if err != nil {
+			return nil, newParseError(lineNum, recordType, -1, err)
+		}
+	// edge line (E)
+	case "E":
+		line, err = NewEdge(fields[1], fields[2], fields[3], fields[4], fields[5], fields[6], fields[7], fields[8])
+		if err != nil {
+			return nil, newParseError(lineNum, recordType, -1, err)
+		}
+	// fragment line (F)
+	case "F":
+		line, err = NewFragment(fields[1], fields[2], fields[3], fields[4], fields[5], fields[6], fields[7])
+		if err != nil {
+			return nil, newParseError(lineNum, recordType, -1, err)
+		}
+	// gap line (G)
+	case "G":
+		line, err = NewGap(fields[1], fields[2], fields[3], fields[4], fields[5])
+		if err != nil {
+			return nil, newParseError(lineNum, recordType, -1, err)
+		}
+	// ordered group line (O)
+	case "O":
+		line, err = NewOrderedGroup(fields[1], bytes.Split(fields[2], []byte(" ")))
+		if err != nil {
+			return nil, newParseError(lineNum, recordType, -1, err)
+		}
+	// unordered group line (U)
+	case "U":
+		line, err = NewUnorderedGroup(fields[1], bytes.Split(fields[2], []byte(" ")))
+		if err != nil {
+			return nil, newParseError(lineNum, recordType, -1, err)
+		}
+	// header line (H)
+	case "H":
+		line, err = newHeader(fields[1:]...)
+		if err != nil {
+			return nil, newParseError(lineNum, recordType, -1, err)
+		}
+		// the header constructor handles the optional fields
+		return line, nil
+	}
+	// the remaining fields are optional fields
+	oFs := new(OptionalFields)
+	for i, field := range fields[required:] {
+		if err := oFs.add(field); err != nil {
+			return nil, newParseError(lineNum, recordType, required+i, err)
+		}
+	}
+	if len(oFs.fields) != 0 {
 		line.AddOptionalFields(oFs)
 	}
 	return line, nil
@@ -132,12 +336,22 @@ func (r *Reader) Read() (gfaLine, error) {
 
 // GFAwriter implements GFA format writing
 type GFAwriter struct {
-	w io.Writer
+	w           io.Writer
+	bgzf        *bgzfWriter // set if the output is BGZF compressed
+	closer      io.Closer   // the file created by Create
+	topological bool        // if set, segments and links are written in topological order
 }
 
 // NewWriter returns a Writer to the given io.Writer
-func NewWriter(w io.Writer, myGFA *GFA) (*GFAwriter, error) {
+func NewWriter(w io.Writer, myGFA *GFA, opts ...WriterOption) (*GFAwriter, error) {
 	writer := &GFAwriter{w: w}
+	for _, opt := range opts {
+		opt(writer)
+	}
+	// a GFA read in lossless mode writes its header and comments in their original place
+	if myGFA.lossless {
+		return writer, nil
+	}
 	_, err := writer.w.Write(myGFA.MarshalHeader())
 	if err != nil {
 		return nil, err
@@ -146,9 +360,15 @@ func NewWriter(w io.Writer, myGFA *GFA) (*GFAwriter, error) {
 }
 
 // Write writes a line to the GFA stream
-func (myWriter *GFAwriter) Write(line gfaLine) error {
+func (myWriter *GFAwriter) Write(line Record) error {
 	b := []byte(line.PrintGFAline())
 	b = append(b, '\n')
 	_, err := myWriter.w.Write(b)
 	return err
 }
+
+// writeRaw writes bytes to the GFA stream as they are
+func (myWriter *GFAwriter) writeRaw(b []byte) error {
+	_, err := myWriter.w.Write(b)
+	return err
+}
@@ -1,8 +1,10 @@
 package gfa
 
 import (
+	"bytes"
 	"io"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -174,3 +176,128 @@ func TestPrintSequence(t *testing.T) {
 		t.Fatal("could not extract sequence from graph")
 	}
 }
+
+// read containment lines and write them back out
+func TestReadContainment(t *testing.T) {
+	input := "H\tVN:Z:1\nS\t1\tAAAAATGACGT\nS\t2\tATGAC\nC\t1\t+\t2\t+\t4\t5M\n"
+	reader, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	myGFA := reader.CollectGFA()
+	for {
+		line, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := line.Add(myGFA); err != nil {
+			t.Fatal(err)
+		}
+	}
+	containments, err := myGFA.GetContainments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containments) != 1 {
+		t.Fatalf("expected 1 containment, got %d", len(containments))
+	}
+	container, _ := containments[0].GetContainer()
+	contained, _ := containments[0].GetContained()
+	if string(container) != "1" || string(contained) != "2" {
+		t.Fatal("containment line was not parsed correctly")
+	}
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, myGFA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := myGFA.WriteGFAContent(writer); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "C\t1\t+\t2\t+\t4\t5M\n") {
+		t.Fatalf("containment was not written: %v", buf.String())
+	}
+	t.Log("checking a short containment line gives an error:")
+	reader, err = NewReader(strings.NewReader("H\tVN:Z:1\nC\t1\t+\t2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.Read(); err != nil {
+		t.Log(err)
+	} else {
+		t.Fatal("short containment line did not give an error")
+	}
+}
+
+// test that malformed lines give a ParseError with the line number and offending field
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		input      string
+		recordType string
+		field      int
+		err        error
+	}{
+		{"L\t1\t+\t2\n", "L", 4, ErrTooFewFields},
+		{"P\tp1\t1+,2+\n", "P", 3, ErrTooFewFields},
+		{"X\tfoo\tbar\n", "X", 0, ErrUnknownRecordType},
+		{"\n", "", -1, ErrEmptyLine},
+		{"E\te1\t1+\t2+\t0\t4\t0\t4\t*\n", "E", 0, ErrWrongVersion},
+		{"L\t1\t+\t2\tx\t*\n", "L", 4, nil},
+		{"S\t2\tACGT\tLN:i:4\tRC:i:x\n", "S", 4, nil},
+	}
+	for _, test := range tests {
+		reader, err := NewReader(strings.NewReader("H\tVN:Z:1\nS\t1\tACGT\n" + test.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := reader.Read(); err != nil {
+			t.Fatal(err)
+		}
+		_, err = reader.Read()
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Fatalf("expected a ParseError for %q, got %v", test.input, err)
+		}
+		if perr.Line != 3 || perr.RecordType != test.recordType || perr.Field != test.field || (test.err != nil && perr.Err != test.err) {
+			t.Fatalf("unexpected ParseError for %q: %+v", test.input, perr)
+		}
+		t.Log(perr)
+	}
+}
+
+// test that a lenient reader skips malformed lines and collects their errors
+func TestLenient(t *testing.T) {
+	input := "H\tVN:Z:1\nH\tVN:Z:9\nS\t1\tACGT\n\nX\tfoo\nL\t1\t+\t2\nS\t2\tTTGA\nL\t1\t+\t2\t-\t0M\n"
+	reader, err := NewReader(strings.NewReader(input), Lenient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	myGFA := reader.CollectGFA()
+	for {
+		line, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := line.Add(myGFA); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(myGFA.segments) != 2 || len(myGFA.links) != 1 {
+		t.Fatalf("expected 2 segments and 1 link, got %d and %d", len(myGFA.segments), len(myGFA.links))
+	}
+	errs := reader.Errors()
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 skipped lines, got %d", len(errs))
+	}
+	for i, line := range []int{2, 4, 5, 6} {
+		if errs[i].Line != line {
+			t.Fatalf("expected skipped line %d, got %d", line, errs[i].Line)
+		}
+	}
+}
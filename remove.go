@@ -0,0 +1,487 @@
+package gfa
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A RemovePolicy decides what RemoveSegment does with the records that reference the segment being removed
+type RemovePolicy int
+
+// The policies that can be given to RemoveSegment
+const (
+	ErrorIfReferenced RemovePolicy = iota // the segment is not removed if any link, containment, jump, path or walk (or GFA2 edge, gap, fragment or group) references it
+	CascadeDelete                         // the records that reference the segment are removed with it, as are GFA2 groups that reference a removed record
+	SplitPaths                            // as CascadeDelete, but paths and walks are split into the parts either side of the segment (GFA2 groups are still removed)
+)
+
+/*
+RemoveSegment removes the segment with the given name from the GFA instance, the policy decides what happens to any records that reference it
+
+// with SplitPaths, each part of a split path is named after the original path with a numbered suffix (e.g. p1.1, p1.2), which is numbered
+again if a path already has that name (e.g. p1.1.1), and keeps the overlaps between its own segments
+
+// the parts of a split walk keep the sample, haplotype and sequence identifiers of the original walk, their sequence range is * once the
+walk has passed a segment with an unknown length
+*/
+func (gfa *GFA) RemoveSegment(name []byte, policy RemovePolicy) error {
+	seg, ok := gfa.GetSegment(name)
+	if !ok {
+		return fmt.Errorf("Segment not found in GFA instance: %v", string(name))
+	}
+	id := seg.id
+	links := gfa.linksOf(id)
+	containments := []*Containment{}
+	for _, containment := range gfa.containments {
+		if containment.container.id() == id || containment.contained.id() == id {
+			containments = append(containments, containment)
+		}
+	}
+	jumps := []*Jump{}
+	for _, jump := range gfa.jumps {
+		if jump.from.id() == id || jump.to.id() == id {
+			jumps = append(jumps, jump)
+		}
+	}
+	paths := append([]*Path(nil), gfa.nodes[id].paths...)
+	walks := []*Walk{}
+	for _, walk := range gfa.walks {
+		if stepsInclude(walk.steps, id) {
+			walks = append(walks, walk)
+		}
+	}
+	edges, gaps, fragments, groups := gfa.gfa2References(name)
+	switch policy {
+	case ErrorIfReferenced:
+		references := len(links) + len(containments) + len(jumps) + len(paths) + len(walks) + len(edges) + len(gaps) + len(fragments) + len(groups)
+		if references != 0 {
+			return fmt.Errorf("Segment %v is referenced by %d record(s) in the GFA instance", string(name), references)
+		}
+	case CascadeDelete, SplitPaths:
+	default:
+		return fmt.Errorf("Unknown remove policy: %d", policy)
+	}
+	for _, link := range links {
+		gfa.removeLink(link)
+	}
+	for _, containment := range containments {
+		gfa.containments = removeFromContainments(gfa.containments, containment)
+		gfa.untrack(containment)
+	}
+	for _, jump := range jumps {
+		gfa.jumps = removeFromJumps(gfa.jumps, jump)
+		gfa.untrack(jump)
+	}
+	for _, edge := range edges {
+		gfa.edges = removeFromEdges(gfa.edges, edge)
+		gfa.untrack(edge)
+	}
+	for _, gap := range gaps {
+		gfa.gaps = removeFromGaps(gfa.gaps, gap)
+		gfa.untrack(gap)
+	}
+	for _, fragment := range fragments {
+		gfa.fragments = removeFromFragments(gfa.fragments, fragment)
+		gfa.untrack(fragment)
+	}
+	for _, group := range groups {
+		gfa.groups = removeFromGroups(gfa.groups, group)
+		gfa.untrack(group)
+	}
+	// in lossless mode, the parts of a split path or walk are written where the original line was
+	for _, path := range paths {
+		at := gfa.tracked(path)
+		gfa.removePath(path)
+		if policy != SplitPaths {
+			continue
+		}
+		added := len(gfa.order)
+		for i, r := range stepRuns(path.steps, func(s segID) bool { return s != id }) {
+			if err := path.part(gfa.partName(path.name, i+1), r).Add(gfa); err != nil {
+				return err
+			}
+		}
+		gfa.moveTracked(added, at)
+	}
+	for _, walk := range walks {
+		at := gfa.tracked(walk)
+		gfa.walks = removeFromWalks(gfa.walks, walk)
+		gfa.untrack(walk)
+		if policy != SplitPaths {
+			continue
+		}
+		added := len(gfa.order)
+		for _, part := range walk.parts(gfa, stepRuns(walk.steps, func(s segID) bool { return s != id })) {
+			if err := part.Add(gfa); err != nil {
+				return err
+			}
+		}
+		gfa.moveTracked(added, at)
+	}
+	segments := make([]*Segment, 0, len(gfa.segments))
+	for _, s := range gfa.segments {
+		if s != seg {
+			segments = append(segments, s)
+		}
+	}
+	gfa.segments = segments
+	gfa.nodes[id].seg = nil
+	gfa.untrack(seg)
+	return nil
+}
+
+// RemoveLink removes a link from the GFA instance
+func (gfa *GFA) RemoveLink(link *Link) error {
+	for _, l := range gfa.links {
+		if l == link {
+			gfa.removeLink(link)
+			return nil
+		}
+	}
+	return fmt.Errorf("Link not found in GFA instance: %v", link.PrintGFAline())
+}
+
+// RemovePath removes every path with the given name from the GFA instance
+func (gfa *GFA) RemovePath(name []byte) error {
+	removed := false
+	for {
+		path, ok := gfa.pathIndex[string(name)]
+		if !ok {
+			break
+		}
+		gfa.removePath(path)
+		removed = true
+	}
+	if !removed {
+		return fmt.Errorf("Path not found in GFA instance: %v", string(name))
+	}
+	return nil
+}
+
+// linksOf returns the links that start from or go to a segment ID, each link is only returned once
+func (gfa *GFA) linksOf(id segID) []*Link {
+	links := []*Link{}
+	node := gfa.nodes[id]
+	for _, lists := range [][2][]*Link{node.linksFrom, node.linksTo} {
+		for _, list := range lists {
+			for _, link := range list {
+				// a link from a segment to itself is in both lists
+				if link.to.id() == id && link.from.id() == id && containsLink(links, link) {
+					continue
+				}
+				links = append(links, link)
+			}
+		}
+	}
+	return links
+}
+
+// removeLink removes a link from the GFA instance and its segment indexes
+func (gfa *GFA) removeLink(link *Link) {
+	gfa.links = removeFromLinks(gfa.links, link)
+	from, to := &gfa.nodes[link.from.id()].linksFrom[link.from&1], &gfa.nodes[link.to.id()].linksTo[link.to&1]
+	*from = removeFromLinks(*from, link)
+	*to = removeFromLinks(*to, link)
+	gfa.untrack(link)
+}
+
+// removePath removes a path from the GFA instance and its indexes
+func (gfa *GFA) removePath(path *Path) {
+	gfa.paths = removeFromPaths(gfa.paths, path)
+	for _, s := range path.steps {
+		gfa.nodes[s.id()].paths = removeFromPaths(gfa.nodes[s.id()].paths, path)
+	}
+	// the path index holds the first path with each name
+	if gfa.pathIndex[string(path.name)] == path {
+		delete(gfa.pathIndex, string(path.name))
+		for _, p := range gfa.paths {
+			if bytes.Equal(p.name, path.name) {
+				gfa.pathIndex[string(path.name)] = p
+				break
+			}
+		}
+	}
+	gfa.untrack(path)
+}
+
+// gfa2References returns the GFA2 edges, gaps and fragments that reference a segment, and the groups that reference the segment, one of
+// those edges or gaps, or another group that is being removed
+func (gfa *GFA) gfa2References(name []byte) ([]*Edge, []*Gap, []*Fragment, []*Group) {
+	removed := map[string]bool{string(name): true}
+	edges := []*Edge{}
+	for _, edge := range gfa.edges {
+		if bytes.Equal(edge.Sid1, name) || bytes.Equal(edge.Sid2, name) {
+			edges = append(edges, edge)
+			removed[string(edge.ID)] = true
+		}
+	}
+	gaps := []*Gap{}
+	for _, gap := range gfa.gaps {
+		if bytes.Equal(gap.Sid1, name) || bytes.Equal(gap.Sid2, name) {
+			gaps = append(gaps, gap)
+			removed[string(gap.ID)] = true
+		}
+	}
+	fragments := []*Fragment{}
+	for _, fragment := range gfa.fragments {
+		if bytes.Equal(fragment.Sid, name) {
+			fragments = append(fragments, fragment)
+		}
+	}
+	// records without an identifier (*) can't be referenced by a group
+	delete(removed, "*")
+	groups := []*Group{}
+	grouped := make(map[*Group]bool)
+	for changed := true; changed; {
+		changed = false
+		for _, group := range gfa.groups {
+			if !grouped[group] && group.referencesAny(removed) {
+				groups = append(groups, group)
+				grouped[group], changed = true, true
+				if string(group.ID) != "*" {
+					removed[string(group.ID)] = true
+				}
+			}
+		}
+	}
+	return edges, gaps, fragments, groups
+}
+
+// tracked returns the position of a line in the line order kept by a GFA instance in lossless mode, or -1 if it is not there
+func (gfa *GFA) tracked(line Record) int {
+	for i, l := range gfa.order {
+		if l == line {
+			return i
+		}
+	}
+	return -1
+}
+
+// moveTracked moves the lines tracked from position from onwards (i.e. those just added) to position at in the line order
+func (gfa *GFA) moveTracked(from, at int) {
+	if !gfa.lossless || at < 0 || from >= len(gfa.order) {
+		return
+	}
+	order := make([]Record, 0, len(gfa.order))
+	order = append(order, gfa.order[:at]...)
+	order = append(order, gfa.order[from:]...)
+	order = append(order, gfa.order[at:from]...)
+	gfa.order = order
+}
+
+// untrack removes a line from the line order kept by a GFA instance in lossless mode
+func (gfa *GFA) untrack(line Record) {
+	if !gfa.lossless {
+		return
+	}
+	for i, l := range gfa.order {
+		if l == line {
+			gfa.order = append(gfa.order[:i], gfa.order[i+1:]...)
+			break
+		}
+	}
+	delete(gfa.raw, line)
+}
+
+// stepRuns returns the [start, end) ranges of the runs of steps for segment IDs that are kept
+func stepRuns(steps []step, keep func(segID) bool) [][2]int {
+	runs := [][2]int{}
+	start := 0
+	for i := 0; i <= len(steps); i++ {
+		if i < len(steps) && keep(steps[i].id()) {
+			continue
+		}
+		if i > start {
+			runs = append(runs, [2]int{start, i})
+		}
+		start = i + 1
+	}
+	return runs
+}
+
+// partName returns the name for a numbered part of a split path, numbering it again until no path in the GFA instance has the name
+func (gfa *GFA) partName(name []byte, i int) []byte {
+	part := fmt.Sprintf("%v.%d", string(name), i)
+	for n := 1; ; n++ {
+		if _, ok := gfa.pathIndex[part]; !ok {
+			return []byte(part)
+		}
+		part = fmt.Sprintf("%v.%d.%d", string(name), i, n)
+	}
+}
+
+// part returns a new path made from a run of the steps of a path, which keeps the overlaps between those steps if the path has them
+func (path *Path) part(name []byte, r [2]int) *Path {
+	part := &Path{
+		recordType: "P",
+		name:       name,
+		steps:      append([]step(nil), path.steps[r[0]:r[1]]...),
+		names:      path.names,
+		overlaps:   "*",
+		optional:   path.optional.clone(),
+	}
+	if overlaps := path.stepOverlaps(); overlaps != nil && r[1]-r[0] > 1 {
+		part.overlaps = strings.Join(overlaps[r[0]:r[1]-1], ",")
+	}
+	return part
+}
+
+// parts returns a new walk for each run of the steps of a walk, the sequence range of each part is worked out from the segment lengths
+// if the walk has a sequence start
+func (walk *Walk) parts(gfa *GFA, runs [][2]int) []*Walk {
+	// the offset of each step from the start of the walk, -1 once a segment length is not known
+	offsets := make([]int, len(walk.steps)+1)
+	for i, s := range walk.steps {
+		seg := gfa.segment(s.id())
+		if offsets[i] < 0 || seg == nil {
+			offsets[i+1] = -1
+			continue
+		}
+		// a segment without a sequence or an LN tag has a length of 0, which would shift every offset after it
+		length, ok := seg.knownLength()
+		if !ok {
+			offsets[i+1] = -1
+			continue
+		}
+		offsets[i+1] = offsets[i] + length
+	}
+	parts := []*Walk{}
+	for _, r := range runs {
+		part := &Walk{
+			recordType: "W",
+			SampleID:   walk.SampleID,
+			hapIndex:   walk.hapIndex,
+			SeqID:      walk.SeqID,
+			seqStart:   -1,
+			seqEnd:     -1,
+			steps:      append([]step(nil), walk.steps[r[0]:r[1]]...),
+			names:      walk.names,
+			optional:   walk.optional.clone(),
+		}
+		if walk.seqStart >= 0 && offsets[r[1]] >= 0 {
+			part.seqStart, part.seqEnd = walk.seqStart+offsets[r[0]], walk.seqStart+offsets[r[1]]
+		}
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// stepsInclude checks if any of the steps are for a segment ID
+func stepsInclude(steps []step, id segID) bool {
+	for _, s := range steps {
+		if s.id() == id {
+			return true
+		}
+	}
+	return false
+}
+
+// containsLink checks if a link is in a list of links
+func containsLink(links []*Link, link *Link) bool {
+	for _, l := range links {
+		if l == link {
+			return true
+		}
+	}
+	return false
+}
+
+// removeFromLinks returns a new list of links without the given link (lists already returned to callers are left unchanged)
+func removeFromLinks(links []*Link, link *Link) []*Link {
+	kept := make([]*Link, 0, len(links))
+	for _, l := range links {
+		if l != link {
+			kept = append(kept, l)
+		}
+	}
+	return kept
+}
+
+// removeFromPaths returns a list of paths without the given path
+func removeFromPaths(paths []*Path, path *Path) []*Path {
+	kept := make([]*Path, 0, len(paths))
+	for _, p := range paths {
+		if p != path {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// removeFromContainments returns a list of containments without the given containment
+func removeFromContainments(containments []*Containment, containment *Containment) []*Containment {
+	kept := make([]*Containment, 0, len(containments))
+	for _, c := range containments {
+		if c != containment {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// removeFromJumps returns a list of jumps without the given jump
+func removeFromJumps(jumps []*Jump, jump *Jump) []*Jump {
+	kept := make([]*Jump, 0, len(jumps))
+	for _, j := range jumps {
+		if j != jump {
+			kept = append(kept, j)
+		}
+	}
+	return kept
+}
+
+// removeFromWalks returns a list of walks without the given walk
+func removeFromWalks(walks []*Walk, walk *Walk) []*Walk {
+	kept := make([]*Walk, 0, len(walks))
+	for _, w := range walks {
+		if w != walk {
+			kept = append(kept, w)
+		}
+	}
+	return kept
+}
+
+// removeFromEdges returns a list of edges without the given edge
+func removeFromEdges(edges []*Edge, edge *Edge) []*Edge {
+	kept := make([]*Edge, 0, len(edges))
+	for _, e := range edges {
+		if e != edge {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// removeFromGaps returns a list of gaps without the given gap
+func removeFromGaps(gaps []*Gap, gap *Gap) []*Gap {
+	kept := make([]*Gap, 0, len(gaps))
+	for _, g := range gaps {
+		if g != gap {
+			kept = append(kept, g)
+		}
+	}
+	return kept
+}
+
+// removeFromFragments returns a list of fragments without the given fragment
+func removeFromFragments(fragments []*Fragment, fragment *Fragment) []*Fragment {
+	kept := make([]*Fragment, 0, len(fragments))
+	for _, f := range fragments {
+		if f != fragment {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// removeFromGroups returns a list of groups without the given group
+func removeFromGroups(groups []*Group, group *Group) []*Group {
+	kept := make([]*Group, 0, len(groups))
+	for _, g := range groups {
+		if g != group {
+			kept = append(kept, g)
+		}
+	}
+	return kept
+}
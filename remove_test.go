@@ -0,0 +1,190 @@
+package gfa
+
+import (
+	"testing"
+)
+
+var removeInput = `H	VN:Z:1
+S	1	ACGT
+S	2	TT
+S	3	GGA
+S	4	C
+L	1	+	2	+	1M
+L	2	+	3	+	0M
+L	3	+	4	+	0M
+L	2	+	2	+	0M
+C	1	+	4	+	2	1M
+P	p1	1+,2+,3+,4+	1M,0M,0M
+P	p2	3+,4+	0M
+W	s1	0	chr1	10	20	>1>2>3>4
+`
+
+// test that a referenced segment is only removed by the cascade and split policies
+func TestRemoveSegment(t *testing.T) {
+	myGFA := readTestGFA(t, removeInput)
+	if err := myGFA.RemoveSegment([]byte("2"), ErrorIfReferenced); err == nil {
+		t.Fatal("removing a referenced segment should fail")
+	}
+	if _, ok := myGFA.GetSegment([]byte("2")); !ok || len(myGFA.links) != 4 {
+		t.Fatal("failed removal changed the GFA instance")
+	}
+	if err := myGFA.RemoveSegment([]byte("5"), CascadeDelete); err == nil {
+		t.Fatal("removing an unknown segment should fail")
+	}
+	if err := myGFA.RemoveSegment([]byte("2"), CascadeDelete); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := myGFA.GetSegment([]byte("2")); ok {
+		t.Fatal("segment was not removed")
+	}
+	if len(myGFA.segments) != 3 || len(myGFA.links) != 1 || len(myGFA.walks) != 0 || len(myGFA.containments) != 1 {
+		t.Fatalf("unexpected records after cascade: %d segments, %d links", len(myGFA.segments), len(myGFA.links))
+	}
+	if _, ok := myGFA.GetPath([]byte("p1")); ok || len(myGFA.PathsThrough([]byte("1"))) != 0 || len(myGFA.LinksFrom([]byte("1"), "+")) != 0 {
+		t.Fatal("indexes still hold the removed records")
+	}
+	if paths := myGFA.PathsThrough([]byte("3")); len(paths) != 1 || string(paths[0].GetName()) != "p2" {
+		t.Fatal("path index lost a path that did not include the segment")
+	}
+	// a segment can be added again once it has been removed
+	seg, _ := NewSegment([]byte("2"), []byte("AA"))
+	if err := seg.Add(myGFA); err != nil {
+		t.Fatal(err)
+	}
+	if err := myGFA.RemoveSegment([]byte("2"), ErrorIfReferenced); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// test that paths and walks are split around a removed segment
+func TestRemoveSegmentSplit(t *testing.T) {
+	myGFA := readTestGFA(t, removeInput)
+	if err := myGFA.RemoveSegment([]byte("2"), SplitPaths); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"p1.1": "P\tp1.1\t1+\t*",
+		"p1.2": "P\tp1.2\t3+,4+\t0M",
+		"p2":   "P\tp2\t3+,4+\t0M",
+	}
+	if len(myGFA.paths) != len(expected) {
+		t.Fatalf("expected %d paths, got %d", len(expected), len(myGFA.paths))
+	}
+	for name, line := range expected {
+		path, ok := myGFA.GetPath([]byte(name))
+		if !ok || path.PrintGFAline() != line {
+			t.Fatalf("unexpected path %v", name)
+		}
+	}
+	if len(myGFA.PathsThrough([]byte("3"))) != 2 {
+		t.Fatal("split path was not indexed")
+	}
+	walks, _ := myGFA.GetWalks()
+	if len(walks) != 2 || walks[0].PrintGFAline() != "W\ts1\t0\tchr1\t10\t14\t>1" || walks[1].PrintGFAline() != "W\ts1\t0\tchr1\t16\t20\t>3>4" {
+		t.Fatalf("walk was not split: %v", walks)
+	}
+}
+
+// test that split paths are not given the name of another path, and that split walks don't guess offsets past a segment of unknown length
+func TestRemoveSegmentSplitNames(t *testing.T) {
+	myGFA := readTestGFA(t, "H\tVN:Z:1\nS\t1\tACGT\nS\t2\tTT\nS\t3\t*\nS\t4\tC\nP\tp1\t1+,2+,4+\t*\nP\tp1.1\t4+\t*\nW\ts1\t0\tchr1\t0\t*\t>1>2>3>2>4\n")
+	if err := myGFA.RemoveSegment([]byte("2"), SplitPaths); err != nil {
+		t.Fatal(err)
+	}
+	paths, _ := myGFA.GetPaths()
+	names := []string{}
+	for _, path := range paths {
+		names = append(names, string(path.GetName()))
+	}
+	if len(names) != 3 || names[0] != "p1.1" || names[1] != "p1.1.1" || names[2] != "p1.2" {
+		t.Fatalf("unexpected path names after split: %v", names)
+	}
+	walks, _ := myGFA.GetWalks()
+	if len(walks) != 3 || walks[0].PrintGFAline() != "W\ts1\t0\tchr1\t0\t4\t>1" || walks[1].PrintGFAline() != "W\ts1\t0\tchr1\t*\t*\t>3" || walks[2].PrintGFAline() != "W\ts1\t0\tchr1\t*\t*\t>4" {
+		t.Fatalf("unexpected walks after split: %v", walks)
+	}
+}
+
+// test removing links and paths directly
+func TestRemoveLinkAndPath(t *testing.T) {
+	myGFA := readTestGFA(t, removeInput)
+	links := myGFA.LinksFrom([]byte("2"), "+")
+	if len(links) != 2 {
+		t.Fatalf("expected 2 links from 2+, got %d", len(links))
+	}
+	for _, link := range links {
+		if err := myGFA.RemoveLink(link); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(myGFA.links) != 2 || len(myGFA.LinksFrom([]byte("2"), "+")) != 0 || len(myGFA.LinksTo([]byte("2"), "+")) != 1 {
+		t.Fatal("links were not removed from the indexes")
+	}
+	if err := myGFA.RemoveLink(links[0]); err == nil {
+		t.Fatal("removing a link twice should fail")
+	}
+	if err := myGFA.RemovePath([]byte("p1")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := myGFA.GetPath([]byte("p1")); ok || len(myGFA.PathsThrough([]byte("1"))) != 0 {
+		t.Fatal("path was not removed")
+	}
+	if err := myGFA.RemovePath([]byte("p1")); err == nil {
+		t.Fatal("removing an unknown path should fail")
+	}
+}
+
+// test that removed lines are left out when a lossless GFA instance is written back
+func TestRemoveLossless(t *testing.T) {
+	myGFA := readLossless(t, []byte(removeInput))
+	if err := myGFA.RemoveSegment([]byte("4"), CascadeDelete); err != nil {
+		t.Fatal(err)
+	}
+	expected := "H\tVN:Z:1\nS\t1\tACGT\nS\t2\tTT\nS\t3\tGGA\nL\t1\t+\t2\t+\t1M\nL\t2\t+\t3\t+\t0M\nL\t2\t+\t2\t+\t0M\n"
+	if output := string(writeGFA(t, myGFA)); output != expected {
+		t.Fatalf("unexpected lossless output:\n%v", output)
+	}
+}
+
+// test that the split parts of a path are written where the path was in lossless mode
+func TestRemoveLosslessSplit(t *testing.T) {
+	myGFA := readLossless(t, []byte(removeInput))
+	if err := myGFA.RemoveSegment([]byte("2"), SplitPaths); err != nil {
+		t.Fatal(err)
+	}
+	expected := "H\tVN:Z:1\nS\t1\tACGT\nS\t3\tGGA\nS\t4\tC\nL\t3\t+\t4\t+\t0M\nC\t1\t+\t4\t+\t2\t1M\nP\tp1.1\t1+\t*\nP\tp1.2\t3+,4+\t0M\nP\tp2\t3+,4+\t0M\nW\ts1\t0\tchr1\t10\t14\t>1\nW\ts1\t0\tchr1\t16\t20\t>3>4\n"
+	if output := string(writeGFA(t, myGFA)); output != expected {
+		t.Fatalf("unexpected lossless output:\n%v", output)
+	}
+}
+
+// test that GFA2 records that reference a segment are counted and removed
+func TestRemoveSegmentGFA2(t *testing.T) {
+	input := `H	VN:Z:2.0
+S	1	4	ACGT
+S	2	2	TT
+S	3	2	GG
+E	e1	1+	2+	4$	4$	0	0	*
+E	e2	2+	3+	2$	2$	0	0	*
+G	g1	1+	3+	10	*
+F	2	read1+	0	2$	0	2	2M
+O	o1	1+ 2+ 3+
+U	u1	e1 3
+U	u2	u1
+U	u3	1 3
+`
+	myGFA := readTestGFA(t, input)
+	if err := myGFA.RemoveSegment([]byte("2"), ErrorIfReferenced); err == nil {
+		t.Fatal("removed a segment referenced by GFA2 records")
+	}
+	if err := myGFA.RemoveSegment([]byte("2"), CascadeDelete); err != nil {
+		t.Fatal(err)
+	}
+	if err := myGFA.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	// u1 references a removed edge, and u2 references u1
+	if len(myGFA.edges) != 0 || len(myGFA.fragments) != 0 || len(myGFA.gaps) != 1 || len(myGFA.groups) != 1 || string(myGFA.groups[0].ID) != "u3" {
+		t.Fatalf("unexpected GFA2 records after removal: %d edges, %d fragments, %d gaps, %d groups", len(myGFA.edges), len(myGFA.fragments), len(myGFA.gaps), len(myGFA.groups))
+	}
+}
@@ -0,0 +1,147 @@
+package gfa
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/biogo/biogo/alphabet"
+	"github.com/biogo/biogo/io/seqio/fasta"
+	"github.com/biogo/biogo/seq/linear"
+)
+
+// SetSequence fills in the sequence of a segment, the sequence must match any LN and SH (SHA-256 checksum) tags the segment has
+func (seg *Segment) SetSequence(seq []byte) error {
+	name := seg.names.name(seg.id)
+	if string(seq) == "*" {
+		return fmt.Errorf("Segment %v can't be given * as a sequence", name)
+	}
+	if err := checkSequence(seq, seg.version); err != nil {
+		return fmt.Errorf("Can't set the sequence of segment %v: %v", name, err)
+	}
+	if reasons := seg.checkTags(seq); len(reasons) != 0 {
+		return fmt.Errorf("Can't set the sequence of segment %v: %v", name, reasons[0])
+	}
+	seg.seq = packSequence(seq)
+	// a GFA2 segment keeps the length it was given
+	if seg.version != 2 {
+		seg.Length = len(seq)
+	}
+	return nil
+}
+
+// ResolveSequences fills in the sequences of any segments without one (*) from a FASTA file, using the records named after the segments
+// (segments that are not in the FASTA file are left without a sequence)
+func (gfa *GFA) ResolveSequences(fastaFile io.Reader) error {
+	r := fasta.NewReader(fastaFile, linear.NewSeq("", nil, alphabet.DNA))
+	for {
+		s, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Can't read FASTA file: %v", err)
+		}
+		seg, ok := gfa.GetSegment([]byte(s.Name()))
+		if !ok || seg.HasSequence() {
+			continue
+		}
+		if err := seg.SetSequence(alphabet.LettersToBytes(s.(*linear.Seq).Seq)); err != nil {
+			return err
+		}
+	}
+}
+
+/*
+ResolveURSequences fills in the sequences of any segments without one (*) from the file given by their UR tag
+
+// the UR tag can be a local path or a file:// URI, relative paths are taken from dir (e.g. the directory of the GFA file)
+
+// the file can hold a plain sequence or be a FASTA file, where the record named after the segment is used (or the only record)
+
+// each file is only read once, however many segments reference it
+*/
+func (gfa *GFA) ResolveURSequences(dir string) error {
+	files := make(map[string]map[string][]byte)
+	for _, seg := range gfa.segments {
+		if seg.HasSequence() || !seg.optional.Has("UR") {
+			continue
+		}
+		name := seg.names.name(seg.id)
+		uri, err := seg.optional.GetString("UR")
+		if err != nil {
+			return fmt.Errorf("Segment %v has a bad UR tag: %v", name, err)
+		}
+		path, err := uriPath(uri, dir)
+		if err != nil {
+			return fmt.Errorf("Segment %v has a bad UR tag: %v", name, err)
+		}
+		records, ok := files[path]
+		if !ok {
+			if records, err = readSequenceFile(path); err != nil {
+				return err
+			}
+			files[path] = records
+		}
+		seq, ok := records[name]
+		// a file with a single sequence is used whatever the sequence is named
+		if !ok && len(records) == 1 {
+			for _, only := range records {
+				seq, ok = only, true
+			}
+		}
+		if !ok {
+			return fmt.Errorf("Sequence for segment %v not found in %v", name, path)
+		}
+		if err := seg.SetSequence(seq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uriPath returns the local path given by a UR tag, relative paths are joined to dir
+func uriPath(uri, dir string) (string, error) {
+	if strings.HasPrefix(uri, "file://") {
+		u, err := url.Parse(uri)
+		if err != nil {
+			return "", err
+		}
+		return u.Path, nil
+	}
+	if strings.Contains(uri, "://") {
+		return "", fmt.Errorf("only local paths and file:// URIs are supported: %v", uri)
+	}
+	if !filepath.IsAbs(uri) {
+		uri = filepath.Join(dir, uri)
+	}
+	return uri, nil
+}
+
+// readSequenceFile reads the sequences in a FASTA file by name, or a plain sequence file as a single unnamed sequence
+func readSequenceFile(path string) (map[string][]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Can't read sequence file: %v", err)
+	}
+	records := make(map[string][]byte)
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte(">")) {
+		records[""] = bytes.Join(bytes.Fields(data), nil)
+		return records, nil
+	}
+	r := fasta.NewReader(bytes.NewReader(data), linear.NewSeq("", nil, alphabet.DNA))
+	for {
+		s, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Can't read FASTA file %v: %v", path, err)
+		}
+		records[s.Name()] = alphabet.LettersToBytes(s.(*linear.Seq).Seq)
+	}
+}
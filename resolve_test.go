@@ -0,0 +1,101 @@
+package gfa
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var placeholderInput = `H	VN:Z:1
+S	1	*	LN:i:4	SH:H:1DFF3E84FE7877E0673B69BBDDCF40124E396E3F9943DD890C91B6A09ADB9AF0
+S	2	*
+S	3	GGA
+L	1	+	3	+	0M
+P	p1	1+,3+	*
+`
+
+// test that segments without a sequence take their length from the LN tag
+func TestPlaceholderSegment(t *testing.T) {
+	myGFA := readTestGFA(t, placeholderInput)
+	seg1, _ := myGFA.GetSegment([]byte("1"))
+	seg2, _ := myGFA.GetSegment([]byte("2"))
+	if seg1.HasSequence() || seg1.GetLength() != 4 || seg2.GetLength() != 0 {
+		t.Fatalf("unexpected placeholder lengths: %d %d", seg1.GetLength(), seg2.GetLength())
+	}
+	if seg2.PrintGFAline() != "S\t2\t*" {
+		t.Fatalf("a length should not be added to a segment without a sequence: %v", seg2.PrintGFAline())
+	}
+	if _, err := seg1.GetSequenceRange(0, 1); err == nil {
+		t.Fatal("segment without a sequence has no range to decode")
+	}
+	if _, err := myGFA.PrintSequence([]byte("p1")); err == nil {
+		t.Fatal("path through a segment without a sequence can't be spelled")
+	}
+	for _, finding := range myGFA.ValidateReport() {
+		if finding.RecordType == "S" {
+			t.Fatalf("unexpected finding for a segment without a sequence: %v", finding)
+		}
+	}
+}
+
+// test that sequences are filled in from a FASTA file and checked against the LN and SH tags
+func TestResolveSequences(t *testing.T) {
+	myGFA := readTestGFA(t, placeholderInput)
+	if err := myGFA.ResolveSequences(strings.NewReader(">1 first segment\nAC\nGT\n>3\nTTTT\n>4\nA\n")); err != nil {
+		t.Fatal(err)
+	}
+	if seq, err := myGFA.PrintSequence([]byte("p1")); err != nil || string(seq) != "ACGTGGA" {
+		t.Fatalf("unexpected path sequence: %v", string(seq))
+	}
+	seg, _ := myGFA.GetSegment([]byte("1"))
+	if !seg.HasSequence() || seg.PrintGFAline() != strings.Replace(strings.Split(placeholderInput, "\n")[1], "*", "ACGT", 1) {
+		t.Fatalf("sequence was not filled in: %v", seg.PrintGFAline())
+	}
+	// the checksum and length are checked
+	myGFA = readTestGFA(t, placeholderInput)
+	if err := myGFA.ResolveSequences(strings.NewReader(">1\nACGA\n")); err == nil {
+		t.Fatal("sequence that does not match the SH tag should not be used")
+	}
+	if err := myGFA.ResolveSequences(strings.NewReader(">1\nACG\n")); err == nil {
+		t.Fatal("sequence that does not match the LN tag should not be used")
+	}
+	if seg, _ := myGFA.GetSegment([]byte("1")); seg.HasSequence() {
+		t.Fatal("segment sequence was set by a failed resolution")
+	}
+}
+
+// test that sequences are filled in from the files given by UR tags
+func TestResolveURSequences(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gfa-resolve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "seqs.fa"), []byte(">1\nACGT\n>2\nTT\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	plain := filepath.Join(dir, "plain.txt")
+	if err := ioutil.WriteFile(plain, []byte("GG\nA\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	input := "H\tVN:Z:1\nS\t1\t*\tUR:Z:seqs.fa\nS\t2\t*\tUR:Z:seqs.fa\nS\t3\t*\tUR:Z:file://" + plain + "\nS\t4\t*\n"
+	myGFA := readTestGFA(t, input)
+	if err := myGFA.ResolveURSequences(dir); err != nil {
+		t.Fatal(err)
+	}
+	for name, expected := range map[string]string{"1": "ACGT", "2": "TT", "3": "GGA", "4": "*"} {
+		if seg, _ := myGFA.GetSegment([]byte(name)); string(seg.GetSequence()) != expected {
+			t.Fatalf("segment %v has sequence %v, expected %v", name, string(seg.GetSequence()), expected)
+		}
+	}
+	myGFA = readTestGFA(t, "H\tVN:Z:1\nS\t1\t*\tUR:Z:https://example.com/1.fa\n")
+	if err := myGFA.ResolveURSequences(dir); err == nil {
+		t.Fatal("remote UR should not be resolved")
+	}
+	myGFA = readTestGFA(t, "H\tVN:Z:1\nS\t5\t*\tUR:Z:seqs.fa\n")
+	if err := myGFA.ResolveURSequences(dir); err == nil {
+		t.Fatal("segment missing from a FASTA file with several records should fail")
+	}
+}
@@ -0,0 +1,145 @@
+package gfa
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// packedBases decodes the 2 bit codes of a packedSequence
+const packedBases = "ACGT"
+
+// baseCodes gives the 2 bit code of each base that can be packed, any other byte is -1
+var baseCodes = func() [256]int8 {
+	var codes [256]int8
+	for i := range codes {
+		codes[i] = -1
+	}
+	for code, base := range []byte(packedBases) {
+		codes[base] = int8(code)
+	}
+	return codes
+}()
+
+// complements gives the complement of each base, including the IUPAC ambiguity codes (e.g. R, A or G, is complemented by Y, C or T),
+// any byte that is not a base is its own complement
+var complements = func() [256]byte {
+	var comp [256]byte
+	for i := range comp {
+		comp[i] = byte(i)
+	}
+	for _, pair := range []string{"AT", "CG", "RY", "KM", "BV", "DH", "SS", "WW", "NN", "at", "cg", "ry", "km", "bv", "dh", "ss", "ww", "nn"} {
+		comp[pair[0]], comp[pair[1]] = pair[1], pair[0]
+	}
+	// U (RNA) is complemented by A, which is complemented by T
+	comp['U'], comp['u'] = 'A', 'a'
+	return comp
+}()
+
+// A packedSequence holds a sequence at 2 bits per base, any bases other than A, C, G and T (such as N, the other IUPAC codes and
+// lower case bases) are held as they were written in a list of exceptions
+type packedSequence struct {
+	length     int
+	bases      []byte      // 4 bases per byte, lowest bits first (exceptions are packed as A)
+	exceptions []exception // in order of position
+}
+
+// an exception is a run of bases in a packedSequence that can't be packed into 2 bits
+type exception struct {
+	pos   int
+	bases string
+}
+
+// packSequence packs a sequence into 2 bits per base, with an exception for each run of bases that are not A, C, G or T
+func packSequence(seq []byte) packedSequence {
+	ps := packedSequence{length: len(seq), bases: make([]byte, (len(seq)+3)/4)}
+	for i := 0; i < len(seq); i++ {
+		code := baseCodes[seq[i]]
+		if code >= 0 {
+			ps.bases[i/4] |= byte(code) << uint(2*(i%4))
+			continue
+		}
+		start := i
+		for i+1 < len(seq) && baseCodes[seq[i+1]] < 0 {
+			i++
+		}
+		ps.exceptions = append(ps.exceptions, exception{pos: start, bases: string(seq[start : i+1])})
+	}
+	return ps
+}
+
+// appendRange decodes the bases from start up to (but not including) end and appends them to dst
+func (ps *packedSequence) appendRange(dst []byte, start, end int) []byte {
+	offset := len(dst)
+	for i := start; i < end; i++ {
+		dst = append(dst, packedBases[ps.bases[i/4]>>uint(2*(i%4))&3])
+	}
+	// overwrite the decoded range with any exceptions that overlap it
+	first := sort.Search(len(ps.exceptions), func(j int) bool {
+		return ps.exceptions[j].pos+len(ps.exceptions[j].bases) > start
+	})
+	for _, ex := range ps.exceptions[first:] {
+		if ex.pos >= end {
+			break
+		}
+		lo, hi := ex.pos, ex.pos+len(ex.bases)
+		if lo < start {
+			lo = start
+		}
+		if hi > end {
+			hi = end
+		}
+		copy(dst[offset+lo-start:], ex.bases[lo-ex.pos:hi-ex.pos])
+	}
+	return dst
+}
+
+// isPlaceholder returns true if the sequence is * (the sequence was not stored)
+func (ps *packedSequence) isPlaceholder() bool {
+	return ps.length == 1 && len(ps.exceptions) == 1 && ps.exceptions[0].bases == "*"
+}
+
+// foldCase returns the sequence with any lower case bases converted to upper case, so that a, c, g and t can be packed
+func (ps *packedSequence) foldCase() packedSequence {
+	for _, ex := range ps.exceptions {
+		if bytes.ContainsAny([]byte(ex.bases), "abcdefghijklmnopqrstuvwxyz") {
+			return packSequence(bytes.ToUpper(ps.appendRange(nil, 0, ps.length)))
+		}
+	}
+	return *ps
+}
+
+// reverseComplement returns the reverse complement of a sequence, preserving case
+func reverseComplement(seq []byte) []byte {
+	rc := make([]byte, len(seq))
+	copy(rc, seq)
+	reverseComplementInPlace(rc)
+	return rc
+}
+
+// reverseComplementInPlace reverse complements a sequence without copying it
+func reverseComplementInPlace(seq []byte) {
+	for i, j := 0, len(seq)-1; i <= j; i, j = i+1, j-1 {
+		seq[i], seq[j] = complements[seq[j]], complements[seq[i]]
+	}
+}
+
+// checkSequence checks that a segment sequence is * or only contains the characters allowed by the GFA version
+// (letters, = and . for GFA1 and any printable character for GFA2)
+func checkSequence(seq []byte, version int) error {
+	if len(seq) == 0 {
+		return fmt.Errorf("Segment must have a sequence (or *)")
+	}
+	if string(seq) == "*" {
+		return nil
+	}
+	for _, base := range seq {
+		switch {
+		case version == 2 && base >= '!' && base <= '~':
+		case (base >= 'A' && base <= 'Z') || (base >= 'a' && base <= 'z') || base == '=' || base == '.':
+		default:
+			return fmt.Errorf("Segment sequence contains an invalid character: %q", base)
+		}
+	}
+	return nil
+}
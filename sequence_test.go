@@ -0,0 +1,143 @@
+package gfa
+
+import (
+	"bytes"
+	"testing"
+)
+
+// test that packed sequences decode to the sequence they were packed from, in whole and in part
+func TestPackSequence(t *testing.T) {
+	for _, seq := range []string{"A", "ACGT", "ACGTA", "TTGCAN", "NNNNACGTNRYacgtNN", "*", "acgtacgtac", "GATTACA.=GATTACA", "WSKMBDHV"} {
+		ps := packSequence([]byte(seq))
+		if ps.length != len(seq) || len(ps.bases) != (len(seq)+3)/4 {
+			t.Fatalf("packed sequence %v has the wrong size", seq)
+		}
+		for start := 0; start <= len(seq); start++ {
+			for end := start; end <= len(seq); end++ {
+				if decoded := ps.appendRange(nil, start, end); string(decoded) != seq[start:end] {
+					t.Fatalf("decoding %v from %d to %d gave %v", seq, start, end, string(decoded))
+				}
+			}
+		}
+		if decoded := ps.appendRange([]byte("prefix"), 0, len(seq)); string(decoded) != "prefix"+seq {
+			t.Fatalf("decoding %v did not append to the existing bytes: %v", seq, string(decoded))
+		}
+	}
+	// runs of bases that can't be packed are held as single exceptions
+	ps := packSequence([]byte("ACNNNGTacgT"))
+	if len(ps.exceptions) != 2 || ps.exceptions[0] != (exception{pos: 2, bases: "NNN"}) || ps.exceptions[1] != (exception{pos: 7, bases: "acg"}) {
+		t.Fatalf("unexpected exceptions: %v", ps.exceptions)
+	}
+	folded := ps.foldCase()
+	if len(folded.exceptions) != 1 || string(folded.appendRange(nil, 0, folded.length)) != "ACNNNGTACGT" {
+		t.Fatalf("folding the case did not pack the lower case bases: %v", folded.exceptions)
+	}
+	placeholder, n := packSequence([]byte("*")), packSequence([]byte("N"))
+	if !placeholder.isPlaceholder() || n.isPlaceholder() {
+		t.Fatal("placeholder sequence not recognised")
+	}
+}
+
+// test the characters allowed in segment sequences
+func TestCheckSequence(t *testing.T) {
+	if _, err := NewSegment([]byte("1"), []byte("AC1T")); err == nil {
+		t.Fatal("GFA1 segment sequence can't contain digits")
+	}
+	if _, err := NewSegment([]byte("1"), []byte("ACGT*")); err == nil {
+		t.Fatal("GFA1 segment sequence can only be * on its own")
+	}
+	if _, err := NewGFA2Segment([]byte("1"), []byte("4"), []byte("AC1T")); err != nil {
+		t.Fatal(err)
+	}
+	seg, err := NewSegment([]byte("1"), []byte("ACGTNnRy"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if part, err := seg.GetSequenceRange(3, 6); err != nil || string(part) != "TNn" {
+		t.Fatalf("unexpected sequence range: %v", string(part))
+	}
+	if _, err := seg.GetSequenceRange(6, 9); err == nil {
+		t.Fatal("sequence range beyond the end of the segment should fail")
+	}
+}
+
+// test that the CaseInsensitive option stores sequences in upper case, without changing lines written back in lossless mode
+func TestCaseInsensitive(t *testing.T) {
+	input := "H\tVN:Z:1\nS\t1\tacgtN\nS\t2\tGGcc\nP\tp1\t1+,2+\t*\n"
+	reader, err := NewReader(bytes.NewReader([]byte(input)), CaseInsensitive(), Lossless())
+	if err != nil {
+		t.Fatal(err)
+	}
+	myGFA, err := reader.ReadAll(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seg, _ := myGFA.GetSegment([]byte("1"))
+	if string(seg.GetSequence()) != "ACGTN" || len(seg.seq.exceptions) != 1 {
+		t.Fatalf("sequence was not stored in upper case: %v", string(seg.GetSequence()))
+	}
+	if seq, err := myGFA.PrintSequence([]byte("p1")); err != nil || string(seq) != "ACGTNGGCC" {
+		t.Fatalf("unexpected path sequence: %v", string(seq))
+	}
+	if output := writeGFA(t, myGFA); string(output) != input {
+		t.Fatalf("lossless output does not match the input:\n%v", string(output))
+	}
+}
+
+// test that reverse complements handle case and the IUPAC ambiguity codes
+func TestReverseComplement(t *testing.T) {
+	if rc := reverseComplement([]byte("ACGTRYKMBVDHSWNacgtrykmbvdhswn.*U")); string(rc) != "A*.nwsdhbvkmryacgtNWSDHBVKMRYACGT" {
+		t.Fatalf("unexpected reverse complement: %v", string(rc))
+	}
+	if rc := reverseComplement([]byte("ACGTA")); string(reverseComplement(rc)) != "ACGTA" {
+		t.Fatal("reverse complementing twice should give the original sequence")
+	}
+}
+
+var orientInput = `H	VN:Z:1
+S	1	ACGTAC
+S	2	TTRGG
+S	3	GGTNa
+L	1	+	2	-	0M
+L	2	-	3	+	2M
+L	3	-	3	+	0M
+P	p1	1+,2-,3+	*
+P	p2	3-,3+	*
+`
+
+// test that reverse steps are reverse complemented, and that any part of a path can be spelled
+func TestOrientedSpelling(t *testing.T) {
+	myGFA := readTestGFA(t, orientInput)
+	for name, expected := range map[string]string{"p1": "ACGTACCCYAATNa", "p2": "tNACCGGTNa"} {
+		seq, err := myGFA.PrintSequence([]byte(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(seq) != expected {
+			t.Fatalf("path %v spelled %v, expected %v", name, string(seq), expected)
+		}
+		for start := 0; start <= len(expected); start++ {
+			for end := start; end <= len(expected); end++ {
+				part, err := myGFA.PrintSequenceRange([]byte(name), start, end)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(part) != expected[start:end] {
+					t.Fatalf("path %v from %d to %d spelled %v, expected %v", name, start, end, string(part), expected[start:end])
+				}
+			}
+			if part, err := myGFA.PrintSequenceRange([]byte(name), start, -1); err != nil || string(part) != expected[start:] {
+				t.Fatalf("path %v from %d spelled %v", name, start, string(part))
+			}
+		}
+		if _, err := myGFA.PrintSequenceRange([]byte(name), 0, len(expected)+1); err == nil {
+			t.Fatal("range beyond the end of the path should fail")
+		}
+	}
+	if _, err := readTestGFA(t, orientInput+"P\tp3\t1+,9+\t*\n").PrintSequence([]byte("p3")); err == nil {
+		t.Fatal("path with a missing segment should fail")
+	}
+	if _, err := myGFA.PrintSequenceRange([]byte("p1"), 3, 2); err == nil {
+		t.Fatal("range with the end before the start should fail")
+	}
+}
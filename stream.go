@@ -0,0 +1,137 @@
+package gfa
+
+import "io"
+
+// Handler receives the records of a GFA file as they are streamed by a Reader
+//
+// returning an error from any method stops the stream
+type Handler interface {
+	OnHeader(*Header) error
+	OnComment(*Comment) error
+	OnSegment(*Segment) error
+	OnLink(*Link) error
+	OnContainment(*Containment) error
+	OnPath(*Path) error
+}
+
+// WalkHandler can be implemented by a Handler to also receive the walk and jump records added in GFA 1.1 and 1.2
+type WalkHandler interface {
+	OnWalk(*Walk) error
+	OnJump(*Jump) error
+}
+
+// GFA2Handler can be implemented by a Handler to also receive the GFA2 edge, fragment, gap and group records
+type GFA2Handler interface {
+	OnEdge(*Edge) error
+	OnFragment(*Fragment) error
+	OnGap(*Gap) error
+	OnGroup(*Group) error
+}
+
+// NopHandler implements every handler method by ignoring the record, embed it in a Handler to only implement the methods you need
+type NopHandler struct{}
+
+// OnHeader ignores a header
+func (NopHandler) OnHeader(*Header) error { return nil }
+
+// OnComment ignores a comment
+func (NopHandler) OnComment(*Comment) error { return nil }
+
+// OnSegment ignores a segment
+func (NopHandler) OnSegment(*Segment) error { return nil }
+
+// OnLink ignores a link
+func (NopHandler) OnLink(*Link) error { return nil }
+
+// OnContainment ignores a containment
+func (NopHandler) OnContainment(*Containment) error { return nil }
+
+// OnPath ignores a path
+func (NopHandler) OnPath(*Path) error { return nil }
+
+// OnWalk ignores a walk
+func (NopHandler) OnWalk(*Walk) error { return nil }
+
+// OnJump ignores a jump
+func (NopHandler) OnJump(*Jump) error { return nil }
+
+// OnEdge ignores an edge
+func (NopHandler) OnEdge(*Edge) error { return nil }
+
+// OnFragment ignores a fragment
+func (NopHandler) OnFragment(*Fragment) error { return nil }
+
+// OnGap ignores a gap
+func (NopHandler) OnGap(*Gap) error { return nil }
+
+// OnGroup ignores a group
+func (NopHandler) OnGroup(*Group) error { return nil }
+
+/*
+Stream reads the remaining lines from the reader and passes each record to the handler, the records are not added to the GFA instance held by the reader
+
+// the header and comment lines read by NewReader are passed to the handler first
+
+// walks and jumps are skipped unless the handler implements WalkHandler, GFA2 records are skipped unless it implements GFA2Handler
+
+// a Reader in lossless mode keeps a copy of every line, so should not be used to stream large files
+*/
+func (r *Reader) Stream(h Handler) error {
+	for _, line := range r.preamble {
+		if err := handle(h, line); err != nil {
+			return err
+		}
+	}
+	r.preamble = nil
+	for {
+		line, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := handle(h, line); err != nil {
+			return err
+		}
+	}
+}
+
+// handle passes a line to the handler method for its record type
+func handle(h Handler, line Record) error {
+	switch record := line.(type) {
+	case *Header:
+		return h.OnHeader(record)
+	case *Comment:
+		return h.OnComment(record)
+	case *Segment:
+		return h.OnSegment(record)
+	case *Link:
+		return h.OnLink(record)
+	case *Containment:
+		return h.OnContainment(record)
+	case *Path:
+		return h.OnPath(record)
+	}
+	if wh, ok := h.(WalkHandler); ok {
+		switch record := line.(type) {
+		case *Walk:
+			return wh.OnWalk(record)
+		case *Jump:
+			return wh.OnJump(record)
+		}
+	}
+	if gh, ok := h.(GFA2Handler); ok {
+		switch record := line.(type) {
+		case *Edge:
+			return gh.OnEdge(record)
+		case *Fragment:
+			return gh.OnFragment(record)
+		case *Gap:
+			return gh.OnGap(record)
+		case *Group:
+			return gh.OnGroup(record)
+		}
+	}
+	return nil
+}
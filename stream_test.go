@@ -0,0 +1,90 @@
+package gfa
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+// countHandler counts the records it is given
+type countHandler struct {
+	NopHandler
+	headers, comments, segments, links, paths, edges int
+}
+
+func (h *countHandler) OnHeader(*Header) error   { h.headers++; return nil }
+func (h *countHandler) OnComment(*Comment) error { h.comments++; return nil }
+func (h *countHandler) OnSegment(*Segment) error { h.segments++; return nil }
+func (h *countHandler) OnLink(*Link) error       { h.links++; return nil }
+func (h *countHandler) OnPath(*Path) error       { h.paths++; return nil }
+
+// gfa2Handler also counts GFA2 edges
+type gfa2Handler struct {
+	countHandler
+}
+
+func (h *gfa2Handler) OnEdge(*Edge) error { h.edges++; return nil }
+
+// test streaming the example GFA gives the same records as reading it into a GFA instance
+func TestStream(t *testing.T) {
+	fh, err := os.Open(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fh.Close()
+	reader, err := NewReader(fh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &countHandler{}
+	if err := reader.Stream(h); err != nil {
+		t.Fatal(err)
+	}
+	input, err := ioutil.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	myGFA := readTestGFA(t, string(input))
+	if h.headers != 1 || h.segments != len(myGFA.segments) || h.links != len(myGFA.links) || h.paths != len(myGFA.paths) {
+		t.Fatalf("streamed record counts do not match: %+v", h)
+	}
+	if len(reader.CollectGFA().segments) != 0 {
+		t.Fatal("streamed records should not be added to the GFA instance")
+	}
+}
+
+// test that GFA2 records are only passed to a handler implementing GFA2Handler, and that a handler error stops the stream
+func TestStreamGFA2(t *testing.T) {
+	reader, err := NewReader(strings.NewReader(testGFA2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &gfa2Handler{}
+	if err := reader.Stream(h); err != nil {
+		t.Fatal(err)
+	}
+	if h.edges == 0 {
+		t.Fatal("edges were not passed to the GFA2 handler")
+	}
+	reader, err = NewReader(strings.NewReader("H\tVN:Z:1\nS\t1\tACGT\nS\t2\tACGT\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := &stopHandler{}
+	if err := reader.Stream(stop); err == nil || stop.segments != 1 {
+		t.Fatal("handler error did not stop the stream")
+	}
+}
+
+// stopHandler returns an error on the first segment
+type stopHandler struct {
+	NopHandler
+	segments int
+}
+
+func (h *stopHandler) OnSegment(*Segment) error {
+	h.segments++
+	return fmt.Errorf("stop")
+}
@@ -0,0 +1,253 @@
+package gfa
+
+import (
+	"container/heap"
+	"fmt"
+)
+
+// A RadiusUnit sets how the radius given to SubgraphAround is measured
+type RadiusUnit int
+
+// The units of the radius given to SubgraphAround
+const (
+	RadiusSteps RadiusUnit = iota // segments up to radius links or containments away from the seed
+	RadiusBases                   // segments with no more than radius bases of other segments between them and the seed
+)
+
+/*
+Subgraph returns a new GFA instance holding the named segments of a GFA version 1 instance
+
+// the links, containments and jumps between the segments are kept
+
+// paths and walks are clipped to the runs of steps that stay within the segments, a clipped path is named after the original path with
+the offsets of the clipped part in the path sequence (e.g. p1:120-450, 0-based and not including the end), steps onto segments that are
+not in the GFA instance are clipped too, and a part after such a step is named with the range of its steps instead (e.g. p1:#4-9)
+
+// the records are copied, so the subgraph can be changed without changing the original
+*/
+func (gfa *GFA) Subgraph(names [][]byte) (*GFA, error) {
+	keep := make([]bool, len(gfa.nodes))
+	for _, name := range names {
+		seg, ok := gfa.GetSegment(name)
+		if !ok {
+			return nil, fmt.Errorf("Segment not found in GFA instance: %v", string(name))
+		}
+		keep[seg.id] = true
+	}
+	return gfa.induced(keep)
+}
+
+// SubgraphByPath returns the subgraph of the segments visited by part of a path, from the 0-based start up to (but not including) end
+// in the sequence spelled by the path
+func (gfa *GFA) SubgraphByPath(pathName []byte, start, end int) (*GFA, error) {
+	path, ok := gfa.pathIndex[string(pathName)]
+	if !ok {
+		return nil, fmt.Errorf("Path not found in GFA instance: %v", string(pathName))
+	}
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("Sequence range %d-%d is not valid", start, end)
+	}
+	skips, err := gfa.stepSkips(path.names, path.steps, path.stepOverlaps())
+	if err != nil {
+		return nil, err
+	}
+	keep := make([]bool, len(gfa.nodes))
+	pos := 0
+	for i, s := range path.steps {
+		segStart := pos
+		pos += gfa.segment(s.id()).Length - skips[i]
+		if segStart < end && pos > start {
+			keep[s.id()] = true
+		}
+	}
+	if end > pos {
+		return nil, fmt.Errorf("Sequence range %d-%d is beyond the end of path %v (length %d)", start, end, string(pathName), pos)
+	}
+	return gfa.induced(keep)
+}
+
+// SubgraphAround returns the subgraph of the segments within a radius of a seed segment, following links and containments in
+// either direction, the radius is counted in steps or in bases (using the segment lengths, without taking overlaps into account)
+func (gfa *GFA) SubgraphAround(seed []byte, radius int, unit RadiusUnit) (*GFA, error) {
+	seg, ok := gfa.GetSegment(seed)
+	if !ok {
+		return nil, fmt.Errorf("Segment not found in GFA instance: %v", string(seed))
+	}
+	if radius < 0 {
+		return nil, fmt.Errorf("Radius can't be negative: %d", radius)
+	}
+	if unit != RadiusSteps && unit != RadiusBases {
+		return nil, fmt.Errorf("Unknown radius unit: %d", unit)
+	}
+	neighbours := gfa.neighbours()
+	// distances are found with Dijkstra's algorithm, moving on from a segment costs 1 step or the length of the segment
+	// (moving on from the seed costs no bases)
+	dist := make([]int, len(gfa.nodes))
+	for i := range dist {
+		dist[i] = -1
+	}
+	dist[seg.id] = 0
+	queue := &distanceQueue{{id: seg.id}}
+	for queue.Len() != 0 {
+		next := heap.Pop(queue).(distance)
+		if next.dist > dist[next.id] {
+			continue
+		}
+		cost := 1
+		if unit == RadiusBases {
+			cost = gfa.segment(next.id).Length
+			if next.id == seg.id {
+				cost = 0
+			}
+		}
+		for _, id := range neighbours[next.id] {
+			if d := next.dist + cost; d <= radius && (dist[id] == -1 || d < dist[id]) {
+				dist[id] = d
+				heap.Push(queue, distance{id: id, dist: d})
+			}
+		}
+	}
+	keep := make([]bool, len(gfa.nodes))
+	for id, d := range dist {
+		keep[id] = d != -1
+	}
+	return gfa.induced(keep)
+}
+
+// neighbours returns the segment IDs joined to each segment ID by a link or containment, in either direction
+// (segments that are missing from the GFA instance have no neighbours)
+func (gfa *GFA) neighbours() [][]segID {
+	neighbours := make([][]segID, len(gfa.nodes))
+	join := func(a, b segID) {
+		if gfa.segment(a) != nil && gfa.segment(b) != nil {
+			neighbours[a] = append(neighbours[a], b)
+			neighbours[b] = append(neighbours[b], a)
+		}
+	}
+	for _, link := range gfa.links {
+		join(link.from.id(), link.to.id())
+	}
+	for _, containment := range gfa.containments {
+		join(containment.container.id(), containment.contained.id())
+	}
+	return neighbours
+}
+
+// a distance is a segment ID and its distance from a seed segment
+type distance struct {
+	id   segID
+	dist int
+}
+
+// a distanceQueue is a priority queue of distances, nearest first
+type distanceQueue []distance
+
+func (q distanceQueue) Len() int            { return len(q) }
+func (q distanceQueue) Less(i, j int) bool  { return q[i].dist < q[j].dist }
+func (q distanceQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *distanceQueue) Push(x interface{}) { *q = append(*q, x.(distance)) }
+func (q *distanceQueue) Pop() interface{} {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
+
+// induced returns a new GFA instance holding copies of the segments that are kept, the records between them and the runs of paths
+// and walks that stay within them
+func (gfa *GFA) induced(keep []bool) (*GFA, error) {
+	if gfa.GetVersion() != 1 {
+		return nil, fmt.Errorf("Can only extract a subgraph from a GFA version 1 instance")
+	}
+	sub := gfa.emptyCopy()
+	kept := func(id segID) bool {
+		return keep[id]
+	}
+	for _, seg := range gfa.segments {
+		if keep[seg.id] {
+			if err := seg.clone().Add(sub); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, link := range gfa.links {
+		if keep[link.from.id()] && keep[link.to.id()] {
+			clone := *link
+			clone.optional = link.optional.clone()
+			clone.Add(sub)
+		}
+	}
+	for _, containment := range gfa.containments {
+		if keep[containment.container.id()] && keep[containment.contained.id()] {
+			clone := *containment
+			clone.optional = containment.optional.clone()
+			clone.Add(sub)
+		}
+	}
+	for _, jump := range gfa.jumps {
+		if keep[jump.from.id()] && keep[jump.to.id()] {
+			clone := *jump
+			clone.optional = jump.optional.clone()
+			clone.Add(sub)
+		}
+	}
+	for _, path := range gfa.paths {
+		runs := stepRuns(path.steps, kept)
+		if len(runs) == 0 {
+			continue
+		}
+		if len(runs) == 1 && runs[0] == [2]int{0, len(path.steps)} {
+			clone := *path
+			clone.steps = append([]step(nil), path.steps...)
+			clone.optional = path.optional.clone()
+			clone.Add(sub)
+			continue
+		}
+		// the parts are named with their offsets, which include the bases of the first step that are overlapped by the step before it,
+		// the offsets are only known up to the first step onto a segment that is not in the GFA instance
+		known := 0
+		for known < len(path.steps) && gfa.segment(path.steps[known].id()) != nil {
+			known++
+		}
+		skips, err := gfa.stepSkips(path.names, path.steps[:known], path.stepOverlaps())
+		if err != nil {
+			return nil, fmt.Errorf("Can't clip path %v: %v", string(path.name), err)
+		}
+		starts, ends := make([]int, known), make([]int, known)
+		pos := 0
+		for i, s := range path.steps[:known] {
+			starts[i] = pos - skips[i]
+			pos += gfa.segment(s.id()).Length - skips[i]
+			ends[i] = pos
+		}
+		for _, r := range runs {
+			name := fmt.Sprintf("%v:#%d-%d", string(path.name), r[0], r[1])
+			if r[1] <= known {
+				name = fmt.Sprintf("%v:%d-%d", string(path.name), starts[r[0]], ends[r[1]-1])
+			}
+			path.part([]byte(name), r).Add(sub)
+		}
+	}
+	for _, walk := range gfa.walks {
+		runs := stepRuns(walk.steps, kept)
+		if len(runs) == 1 && runs[0] == [2]int{0, len(walk.steps)} {
+			clone := *walk
+			clone.steps = append([]step(nil), walk.steps...)
+			clone.optional = walk.optional.clone()
+			clone.Add(sub)
+			continue
+		}
+		for _, part := range walk.parts(gfa, runs) {
+			part.Add(sub)
+		}
+	}
+	return sub, nil
+}
+
+// emptyCopy returns a new GFA instance with a copy of the header and comments of the GFA instance
+func (gfa *GFA) emptyCopy() *GFA {
+	newGFA := NewGFA()
+	newGFA.header = &Header{recordType: "H", vn: gfa.header.vn, version: gfa.header.version, optional: gfa.header.optional.clone()}
+	newGFA.comments = append(newGFA.comments, gfa.comments...)
+	return newGFA
+}
@@ -0,0 +1,123 @@
+package gfa
+
+import (
+	"testing"
+)
+
+var subgraphInput = `H	VN:Z:1
+S	1	ACGT
+S	2	TT
+S	3	GGA
+S	4	C
+S	5	AAAA
+S	6	G
+L	1	+	2	+	0M
+L	2	+	3	+	0M
+L	2	+	4	+	0M
+L	3	+	5	+	0M
+L	4	+	5	+	0M
+L	5	+	6	+	0M
+P	p1	1+,2+,3+,5+,6+	*
+W	s1	0	chr1	10	22	>1>2>4>5>6
+`
+
+// test extracting the subgraph of a set of segments, with the paths and walks clipped to it
+func TestSubgraph(t *testing.T) {
+	myGFA := readTestGFA(t, subgraphInput)
+	sub, err := myGFA.Subgraph([][]byte{[]byte("2"), []byte("3"), []byte("5")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "H\tVN:Z:1\nS\t2\tTT\tLN:i:2\nS\t3\tGGA\tLN:i:3\nS\t5\tAAAA\tLN:i:4\nL\t2\t+\t3\t+\t0M\nL\t3\t+\t5\t+\t0M\nP\tp1:4-13\t2+,3+,5+\t*\nW\ts1\t0\tchr1\t14\t16\t>2\nW\ts1\t0\tchr1\t17\t21\t>5\n"
+	if output := string(writeGFA(t, sub)); output != expected {
+		t.Fatalf("unexpected subgraph:\n%v", output)
+	}
+	if seq, err := sub.PrintSequence([]byte("p1:4-13")); err != nil || string(seq) != "TTGGAAAAA" {
+		t.Fatalf("clipped path spelled %v", string(seq))
+	}
+	// the same segments are visited by bases 5 to 10 of the path
+	byPath, err := myGFA.SubgraphByPath([]byte("p1"), 5, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output := string(writeGFA(t, byPath)); output != expected {
+		t.Fatalf("unexpected subgraph for a path range:\n%v", output)
+	}
+	if _, err := myGFA.SubgraphByPath([]byte("p1"), 5, 15); err == nil {
+		t.Fatal("range beyond the end of the path should fail")
+	}
+	if _, err := myGFA.Subgraph([][]byte{[]byte("7")}); err == nil {
+		t.Fatal("unknown segment should fail")
+	}
+	// the original GFA instance is not changed
+	if len(myGFA.paths) != 1 || len(myGFA.walks) != 1 || string(myGFA.paths[0].GetName()) != "p1" {
+		t.Fatal("extracting a subgraph changed the original GFA instance")
+	}
+}
+
+// test that steps onto segments that are not in the GFA instance are clipped instead of failing the extraction
+func TestSubgraphMissingSegment(t *testing.T) {
+	myGFA := readTestGFA(t, subgraphInput+"P\tp2\t1+,2+,9+,3+,5+\t*\nW\ts2\t0\tchr1\t0\t*\t>1>9>2\n")
+	sub, err := myGFA.Subgraph([][]byte{[]byte("2"), []byte("3"), []byte("5")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths, _ := sub.GetPaths()
+	if len(paths) != 3 || paths[1].PrintGFAline() != "P\tp2:4-6\t2+\t*" || paths[2].PrintGFAline() != "P\tp2:#3-5\t3+,5+\t*" {
+		t.Fatalf("unexpected clipped paths: %v", paths)
+	}
+	walks, _ := sub.GetWalks()
+	if len(walks) != 3 || walks[2].PrintGFAline() != "W\ts2\t0\tchr1\t*\t*\t>2" {
+		t.Fatalf("unexpected clipped walks: %v", walks)
+	}
+	if _, err := myGFA.SubgraphAround([]byte("2"), 1, RadiusSteps); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// test extracting the segments around a seed segment, by steps and by bases
+func TestSubgraphAround(t *testing.T) {
+	myGFA := readTestGFA(t, subgraphInput)
+	for _, test := range []struct {
+		seed     string
+		radius   int
+		unit     RadiusUnit
+		expected []string
+	}{
+		{"4", 0, RadiusSteps, []string{"4"}},
+		{"4", 1, RadiusSteps, []string{"2", "4", "5"}},
+		{"1", 2, RadiusSteps, []string{"1", "2", "3", "4"}},
+		{"1", 0, RadiusBases, []string{"1", "2"}},
+		{"1", 3, RadiusBases, []string{"1", "2", "3", "4", "5"}},
+		{"6", 100, RadiusBases, []string{"1", "2", "3", "4", "5", "6"}},
+	} {
+		sub, err := myGFA.SubgraphAround([]byte(test.seed), test.radius, test.unit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		segs, _ := sub.GetSegments()
+		if len(segs) != len(test.expected) {
+			t.Fatalf("expected %d segments around %v, got %d", len(test.expected), test.seed, len(segs))
+		}
+		for i, seg := range segs {
+			if string(seg.GetName()) != test.expected[i] {
+				t.Fatalf("unexpected segment %v around %v", string(seg.GetName()), test.seed)
+			}
+		}
+	}
+	sub, err := myGFA.SubgraphAround([]byte("4"), 1, RadiusSteps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths, _ := sub.GetPaths()
+	if len(paths) != 2 || string(paths[0].GetName()) != "p1:4-6" || string(paths[1].GetName()) != "p1:9-13" {
+		t.Fatalf("unexpected clipped paths: %v", paths)
+	}
+	links, _ := sub.GetLinks()
+	if len(links) != 2 {
+		t.Fatalf("expected the 2 links between the segments, got %d", len(links))
+	}
+	if _, err := myGFA.SubgraphAround([]byte("4"), -1, RadiusSteps); err == nil {
+		t.Fatal("negative radius should fail")
+	}
+}
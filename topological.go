@@ -0,0 +1,241 @@
+package gfa
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// A SortResult holds the order found by TopologicalSort and the links that could not be fitted to it
+type SortResult struct {
+	Order         []Handle // every segment once, in the orientation it is traversed in
+	FeedbackLinks []*Link  // links that go backwards in the order, which were broken to order a cyclic graph
+	Inversions    []*Link  // links that join the segments in a different relative orientation to the one used in the order
+	SelfLoops     []*Link  // links from a segment to itself (in either orientation)
+	ids           []segID  // the segment IDs of the order
+	reverse       []bool   // the orientation of each segment ID
+	links         []orderedLink
+}
+
+// IsAcyclic returns true if every link (other than inversions) goes forwards in the order, so the graph has no cycles
+func (result *SortResult) IsAcyclic() bool {
+	return len(result.FeedbackLinks) == 0 && len(result.SelfLoops) == 0
+}
+
+// an orderedLink is a link read in the direction that it is traversed when the segments are in their chosen orientations
+type orderedLink struct {
+	from, to segID
+	link     *Link
+}
+
+/*
+TopologicalSort orders the segments of the GFA instance so that, as far as possible, each link goes from an earlier segment to a later one
+
+// segments are given an orientation first, starting from the first segment of each connected component (which is kept forward) and
+following links outwards, a link that needs a segment in the other orientation to the one it already has is an inversion
+
+// segments are then ordered by Kahn's algorithm, taking the segments that are ready in the order they were added to the GFA instance,
+if a cycle means no segment is ready then the segment with the fewest unvisited links into it (the earliest of them if there is a tie) is taken
+next and those links are feedback links
+
+// links to segments that are not in the GFA instance are ignored
+*/
+func (gfa *GFA) TopologicalSort() *SortResult {
+	result := &SortResult{Order: make([]Handle, 0, len(gfa.segments)), ids: make([]segID, 0, len(gfa.segments))}
+	// index is the position of each segment ID in the segments of the GFA instance
+	index := make([]int, len(gfa.nodes))
+	for i := range index {
+		index[i] = -1
+	}
+	for i, seg := range gfa.segments {
+		index[seg.id] = i
+	}
+	touching := make([][]*Link, len(gfa.nodes))
+	for _, link := range gfa.links {
+		a, b := link.from.id(), link.to.id()
+		switch {
+		case index[a] == -1 || index[b] == -1:
+		case a == b:
+			result.SelfLoops = append(result.SelfLoops, link)
+		default:
+			touching[a] = append(touching[a], link)
+			touching[b] = append(touching[b], link)
+		}
+	}
+
+	// orient the segments, the orientation of the far end of a link follows from the orientation of the near end
+	reverse := make([]bool, len(gfa.nodes))
+	oriented := make([]bool, len(gfa.nodes))
+	inverted := make(map[*Link]bool)
+	for _, seg := range gfa.segments {
+		if oriented[seg.id] {
+			continue
+		}
+		oriented[seg.id] = true
+		queue := []segID{seg.id}
+		for len(queue) != 0 {
+			id := queue[0]
+			queue = queue[1:]
+			for _, link := range touching[id] {
+				near, far := link.from, link.to
+				if far.id() == id {
+					near, far = far, near
+				}
+				farReverse := far.reverse() != (near.reverse() != reverse[id])
+				switch {
+				case !oriented[far.id()]:
+					oriented[far.id()], reverse[far.id()] = true, farReverse
+					queue = append(queue, far.id())
+				case reverse[far.id()] != farReverse:
+					inverted[link] = true
+				}
+			}
+		}
+	}
+	edges := make([][]orderedLink, len(gfa.nodes))
+	inDegree := make([]int, len(gfa.nodes))
+	ordered := []orderedLink{}
+	for _, link := range gfa.links {
+		a, b := link.from.id(), link.to.id()
+		if index[a] == -1 || index[b] == -1 || a == b {
+			continue
+		}
+		if inverted[link] {
+			result.Inversions = append(result.Inversions, link)
+			continue
+		}
+		edge := orderedLink{from: a, to: b, link: link}
+		if link.from.reverse() != reverse[a] {
+			edge.from, edge.to = b, a
+		}
+		edges[edge.from] = append(edges[edge.from], edge)
+		inDegree[edge.to]++
+		ordered = append(ordered, edge)
+	}
+
+	// order the segments, breaking a cycle whenever no segment is ready
+	done := make([]bool, len(gfa.nodes))
+	ready := &indexQueue{}
+	waiting := &degreeQueue{}
+	for i, seg := range gfa.segments {
+		if inDegree[seg.id] == 0 {
+			*ready = append(*ready, i)
+		} else {
+			*waiting = append(*waiting, degreeEntry{inDegree: inDegree[seg.id], index: i})
+		}
+	}
+	heap.Init(ready)
+	heap.Init(waiting)
+	for len(result.ids) != len(gfa.segments) {
+		if ready.Len() == 0 {
+			// entries for segments that have been ordered, or whose in-degree has dropped since, are stale
+			for {
+				entry := heap.Pop(waiting).(degreeEntry)
+				if id := gfa.segments[entry.index].id; !done[id] && inDegree[id] == entry.inDegree {
+					heap.Push(ready, entry.index)
+					break
+				}
+			}
+		}
+		id := gfa.segments[heap.Pop(ready).(int)].id
+		if done[id] {
+			continue
+		}
+		done[id] = true
+		result.ids = append(result.ids, id)
+		result.Order = append(result.Order, Handle{Name: gfa.names.name(id), Reverse: reverse[id]})
+		for _, edge := range edges[id] {
+			inDegree[edge.to]--
+			switch {
+			case done[edge.to]:
+			case inDegree[edge.to] == 0:
+				heap.Push(ready, index[edge.to])
+			default:
+				heap.Push(waiting, degreeEntry{inDegree: inDegree[edge.to], index: index[edge.to]})
+			}
+		}
+	}
+	position := make([]int, len(gfa.nodes))
+	for i, id := range result.ids {
+		position[id] = i
+	}
+	for _, edge := range ordered {
+		if position[edge.from] > position[edge.to] {
+			result.FeedbackLinks = append(result.FeedbackLinks, edge.link)
+		}
+	}
+	result.reverse, result.links = reverse, ordered
+	return result
+}
+
+// an indexQueue is a priority queue of positions in the segments of a GFA instance, lowest first
+type indexQueue []int
+
+func (q indexQueue) Len() int            { return len(q) }
+func (q indexQueue) Less(i, j int) bool  { return q[i] < q[j] }
+func (q indexQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *indexQueue) Push(x interface{}) { *q = append(*q, x.(int)) }
+func (q *indexQueue) Pop() interface{} {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
+
+// a degreeEntry is a segment that is waiting to be ordered, given by its position in the segments of a GFA instance, along with its
+// in-degree when the entry was made
+type degreeEntry struct {
+	inDegree int
+	index    int
+}
+
+// a degreeQueue is a priority queue of the segments waiting to be ordered, fewest unvisited links into them first (then earliest in the
+// segments of the GFA instance), so a cycle can be broken without searching every segment
+type degreeQueue []degreeEntry
+
+func (q degreeQueue) Len() int { return len(q) }
+func (q degreeQueue) Less(i, j int) bool {
+	return q[i].inDegree < q[j].inDegree || (q[i].inDegree == q[j].inDegree && q[i].index < q[j].index)
+}
+func (q degreeQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *degreeQueue) Push(x interface{}) { *q = append(*q, x.(degreeEntry)) }
+func (q *degreeQueue) Pop() interface{} {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
+
+// TopologicalOrder sets a GFAwriter to write segments in the order found by TopologicalSort, followed by the links in the order of the
+// earliest segment they join (a GFA instance read in lossless mode is still written in its original order)
+func TopologicalOrder() WriterOption {
+	return func(writer *GFAwriter) {
+		writer.topological = true
+	}
+}
+
+// topologicalRecords returns the segments and links of the GFA instance in topological order
+func (gfa *GFA) topologicalRecords() ([]*Segment, []*Link) {
+	result := gfa.TopologicalSort()
+	segments := make([]*Segment, len(result.ids))
+	position := make([]int, len(gfa.nodes))
+	for i, id := range result.ids {
+		segments[i] = gfa.segment(id)
+		position[id] = i
+	}
+	// links to missing segments go last
+	first := func(link *Link) int {
+		from, to := link.from.id(), link.to.id()
+		if gfa.segment(from) == nil || gfa.segment(to) == nil {
+			return len(segments)
+		}
+		if position[from] < position[to] {
+			return position[from]
+		}
+		return position[to]
+	}
+	links := append([]*Link(nil), gfa.links...)
+	sort.SliceStable(links, func(i, j int) bool {
+		return first(links[i]) < first(links[j])
+	})
+	return segments, links
+}
@@ -0,0 +1,108 @@
+package gfa
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// test that an unordered graph is sorted, with reverse segments oriented to fit the links
+func TestTopologicalSort(t *testing.T) {
+	input := `H	VN:Z:1
+S	3	T
+S	2	G
+S	1	A
+S	4	C
+L	2	+	3	+	0M
+L	1	+	2	+	0M
+L	4	+	1	-	0M
+L	2	+	4	-	0M
+`
+	myGFA := readTestGFA(t, input)
+	result := myGFA.TopologicalSort()
+	if !result.IsAcyclic() || len(result.Inversions) != 0 {
+		t.Fatalf("graph should be acyclic with no inversions: %d %d", len(result.FeedbackLinks), len(result.Inversions))
+	}
+	order := []string{}
+	for _, h := range result.Order {
+		order = append(order, h.String())
+	}
+	// 4 is reached in reverse, and the link written from 4 is read on the other strand as 1+ to 4-
+	if strings.Join(order, ",") != "1+,2+,3+,4-" {
+		t.Fatalf("unexpected order: %v", order)
+	}
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, myGFA, TopologicalOrder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := myGFA.WriteGFAContent(writer); err != nil {
+		t.Fatal(err)
+	}
+	expected := `H	VN:Z:1
+S	1	A	LN:i:1
+S	2	G	LN:i:1
+S	3	T	LN:i:1
+S	4	C	LN:i:1
+L	1	+	2	+	0M
+L	4	+	1	-	0M
+L	2	+	3	+	0M
+L	2	+	4	-	0M
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected sorted output:\n%v", buf.String())
+	}
+}
+
+// test that cycles, self-loops and inversions are reported
+func TestTopologicalSortCycles(t *testing.T) {
+	input := `H	VN:Z:1
+S	1	A
+S	2	G
+S	3	T
+S	4	C
+L	1	+	2	+	0M
+L	2	+	3	+	0M
+L	3	+	1	+	0M
+L	3	+	3	+	0M
+L	1	+	4	+	0M
+L	2	+	4	-	0M
+`
+	myGFA := readTestGFA(t, input)
+	result := myGFA.TopologicalSort()
+	if result.IsAcyclic() {
+		t.Fatal("cyclic graph was not detected")
+	}
+	if len(result.Order) != 4 || result.Order[0].Name != "1" || result.Order[1].Name != "2" {
+		t.Fatalf("unexpected order: %v", result.Order)
+	}
+	if len(result.FeedbackLinks) != 1 || result.FeedbackLinks[0].PrintGFAline() != "L\t3\t+\t1\t+\t0M" {
+		t.Fatalf("unexpected feedback links: %v", result.FeedbackLinks)
+	}
+	if len(result.SelfLoops) != 1 || len(result.Inversions) != 1 || result.Inversions[0].PrintGFAline() != "L\t2\t+\t4\t-\t0M" {
+		t.Fatalf("unexpected self-loops or inversions: %d %d", len(result.SelfLoops), len(result.Inversions))
+	}
+}
+
+// test that a long chain of cycles is broken one cycle at a time, in the order of the chain
+func TestTopologicalSortManyCycles(t *testing.T) {
+	var input strings.Builder
+	input.WriteString("H\tVN:Z:1\n")
+	n := 5000
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&input, "S\ta%d\tA\nS\tb%d\tC\nL\ta%d\t+\tb%d\t+\t0M\nL\tb%d\t+\ta%d\t+\t0M\n", i, i, i, i, i, i)
+		if i != 0 {
+			fmt.Fprintf(&input, "L\tb%d\t+\ta%d\t+\t0M\n", i-1, i)
+		}
+	}
+	result := readTestGFA(t, input.String()).TopologicalSort()
+	if len(result.Order) != 2*n || len(result.FeedbackLinks) != n {
+		t.Fatalf("expected %d segments and %d feedback links, got %d and %d", 2*n, n, len(result.Order), len(result.FeedbackLinks))
+	}
+	for i, h := range result.Order {
+		if expected := fmt.Sprintf("%c%d+", "ab"[i%2], i/2); h.String() != expected {
+			t.Fatalf("segment %d of the order is %v, expected %v", i, h.String(), expected)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package gfa
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+)
+
+// A Severity grades how serious a Finding is
+type Severity int
+
+// The severities of a Finding
+const (
+	SeverityWarning Severity = iota // the GFA instance can be used, but the record is suspect (e.g. a duplicate link)
+	SeverityError                   // the record breaks the GFA spec or references something that is not in the GFA instance
+)
+
+// String returns the severity as a word (warning/error)
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// A Finding records a single problem found by ValidateReport
+type Finding struct {
+	Severity   Severity
+	RecordType string // the type of the record with the problem, empty if the problem is with the GFA instance as a whole
+	Record     string // the GFA formatted line of the record
+	Reason     string
+}
+
+// String prints the finding
+func (f *Finding) String() string {
+	if f.RecordType == "" {
+		return fmt.Sprintf("%v: %v", f.Severity, f.Reason)
+	}
+	return fmt.Sprintf("%v: %v record (%v): %v", f.Severity, f.RecordType, f.Reason, f.Record)
+}
+
+/*
+ValidateReport checks the GFA instance and returns every problem it finds, unlike Validate which stops at the first error
+
+// checks the version and that the GFA instance holds segments formatted for it
+
+// checks segment names and sequences for illegal characters, and that LN and SH tags agree with the sequence
+
+// checks that links, containments, jumps, paths and walks only reference segments held in the GFA instance
+
+// checks for duplicate links, including links written in the other direction (e.g. L 2 - 1 - duplicates L 1 + 2 +)
+
+// checks that paths have one overlap between each pair of steps (an overlap for each step is a warning), and warns about path and walk steps that are not joined by a link
+
+// checks that CIGAR overlaps are not longer than the segments they overlap
+
+// for GFA2, reports the first error found by Validate for the edges, gaps, fragments and groups
+*/
+func (gfa *GFA) ValidateReport() []*Finding {
+	findings := []*Finding{}
+	report := func(severity Severity, line Record, format string, a ...interface{}) {
+		finding := &Finding{Severity: severity, Reason: fmt.Sprintf(format, a...)}
+		if line != nil {
+			finding.RecordType, finding.Record = line.GetRecordType(), line.PrintGFAline()
+		}
+		findings = append(findings, finding)
+	}
+	version := gfa.GetVersion()
+	switch {
+	case version == 0:
+		report(SeverityError, nil, "GFA instance has no version")
+	case version > 2:
+		report(SeverityError, nil, "GFA version not recognised: %d", version)
+	}
+	if len(gfa.segments) == 0 {
+		report(SeverityError, nil, "GFA instance contains no segments")
+	}
+	for _, seg := range gfa.segments {
+		if version != 0 && seg.version != 0 && seg.version != version {
+			report(SeverityError, seg, "segment is not formatted for GFA version %d", version)
+		}
+		for _, reason := range seg.check(version) {
+			report(SeverityError, seg, "%v", reason)
+		}
+	}
+	if version == 2 {
+		if len(gfa.links) != 0 || len(gfa.containments) != 0 || len(gfa.paths) != 0 || len(gfa.walks) != 0 || len(gfa.jumps) != 0 {
+			report(SeverityError, nil, "GFA version 2 can't contain link, containment, path, walk or jump records")
+		}
+		if err := gfa.validateGFA2(); err != nil {
+			report(SeverityError, nil, "%v", err)
+		}
+	} else if len(gfa.edges) != 0 || len(gfa.fragments) != 0 || len(gfa.gaps) != 0 || len(gfa.groups) != 0 {
+		report(SeverityError, nil, "GFA version 1 can't contain edge, fragment, gap or group records")
+	}
+
+	// missing segments are reported once per record, and any checks that need the segment are skipped
+	missing := func(line Record, steps ...step) bool {
+		found := false
+		for i, s := range steps {
+			if gfa.segment(s.id()) != nil || stepsInclude(steps[:i], s.id()) {
+				continue
+			}
+			report(SeverityError, line, "references an unknown segment: %v", gfa.names.name(s.id()))
+			found = true
+		}
+		return found
+	}
+	// overlaps are checked against the length of the end of the first segment and the start of the second
+	checkOverlap := func(line Record, overlap string, from, to step) {
+		if overlap == "*" {
+			return
+		}
+		refLen, queryLen, err := cigarLengths(overlap)
+		if err != nil {
+			report(SeverityError, line, "overlap is not a valid CIGAR: %v", overlap)
+			return
+		}
+		if length, ok := gfa.segment(from.id()).knownLength(); ok && refLen > length {
+			report(SeverityError, line, "overlap %v is longer than segment %v (length %d)", overlap, gfa.names.name(from.id()), length)
+		}
+		if length, ok := gfa.segment(to.id()).knownLength(); ok && queryLen > length {
+			report(SeverityError, line, "overlap %v is longer than segment %v (length %d)", overlap, gfa.names.name(to.id()), length)
+		}
+	}
+	linked := make(map[[2]step]struct{})
+	for _, link := range gfa.links {
+		if missing(link, link.from, link.to) {
+			continue
+		}
+		key := linkKey(link.from, link.to)
+		if _, ok := linked[key]; ok {
+			report(SeverityWarning, link, "duplicate link between %v and %v", gfa.names.handle(link.from), gfa.names.handle(link.to))
+		}
+		linked[key] = struct{}{}
+		checkOverlap(link, link.overlap, link.from, link.to)
+	}
+	for _, containment := range gfa.containments {
+		if missing(containment, containment.container, containment.contained) {
+			continue
+		}
+		checkOverlap(containment, containment.overlap, containment.container, containment.contained)
+		if length, ok := gfa.segment(containment.container.id()).knownLength(); ok && containment.pos > length {
+			report(SeverityError, containment, "position %d is beyond the end of the container (length %d)", containment.pos, length)
+		}
+	}
+	for _, jump := range gfa.jumps {
+		missing(jump, jump.from, jump.to)
+	}
+	// consecutive steps must be joined by a link, in either direction
+	checkSteps := func(line Record, steps []step) {
+		for i := 1; i < len(steps); i++ {
+			if _, ok := linked[linkKey(steps[i-1], steps[i])]; !ok {
+				report(SeverityWarning, line, "no link joins %v to %v", gfa.names.handle(steps[i-1]), gfa.names.handle(steps[i]))
+			}
+		}
+	}
+	for _, path := range gfa.paths {
+		if missing(path, path.steps...) {
+			continue
+		}
+		checkSteps(path, path.steps)
+		if path.overlaps == "*" {
+			continue
+		}
+		// paths converted from an MSA have an overlap for each step, which can't be checked against the segments either side of it
+		overlaps := path.GetOverlaps()
+		switch len(overlaps) {
+		case len(path.steps) - 1:
+		case len(path.steps):
+			report(SeverityWarning, path, "path has an overlap for each step, rather than between each pair of steps")
+			continue
+		default:
+			report(SeverityError, path, "path has %d steps but %d overlaps", len(path.steps), len(overlaps))
+			continue
+		}
+		for i := 1; i < len(path.steps); i++ {
+			checkOverlap(path, overlaps[i-1], path.steps[i-1], path.steps[i])
+		}
+	}
+	for _, walk := range gfa.walks {
+		if missing(walk, walk.steps...) {
+			continue
+		}
+		checkSteps(walk, walk.steps)
+	}
+	return findings
+}
+
+// linkKey returns the same key for a link between two steps and for the equivalent link between the flipped steps in the other direction
+func linkKey(from, to step) [2]step {
+	if reverse := [2]step{to.flip(), from.flip()}; reverse[0] < from || (reverse[0] == from && reverse[1] < to) {
+		return reverse
+	}
+	return [2]step{from, to}
+}
+
+// knownLength returns the length of a segment, taken from the LN tag if the sequence is not stored, ok is false if the length is not known
+func (seg *Segment) knownLength() (int, bool) {
+	if seg.version == 2 || seg.HasSequence() {
+		return seg.Length, true
+	}
+	if length, err := seg.optional.GetInt("LN"); err == nil {
+		return length, true
+	}
+	return seg.Length, seg.Length > 0
+}
+
+// check returns the problems with the name, sequence and tags of a segment
+func (seg *Segment) check(version int) []string {
+	reasons := []string{}
+	name := seg.names.name(seg.id)
+	for i := 0; i < len(name); i++ {
+		if name[i] < '!' || name[i] > '~' {
+			reasons = append(reasons, fmt.Sprintf("segment name contains an illegal character: %q", name[i]))
+			break
+		}
+	}
+	if seg.seq.isPlaceholder() {
+		return reasons
+	}
+	seq := seg.GetSequence()
+	if version != 0 {
+		if err := checkSequence(seq, version); err != nil {
+			reasons = append(reasons, err.Error())
+		}
+	}
+	return append(reasons, seg.checkTags(seq)...)
+}
+
+// checkTags returns the ways in which the LN and SH tags of a segment don't match a sequence
+func (seg *Segment) checkTags(seq []byte) []string {
+	reasons := []string{}
+	if seg.optional.Has("LN") {
+		length, err := seg.optional.GetInt("LN")
+		switch {
+		case err != nil:
+			reasons = append(reasons, fmt.Sprintf("LN tag is not an integer: %v", err))
+		case length != len(seq):
+			reasons = append(reasons, fmt.Sprintf("LN tag (%d) does not match the sequence length (%d)", length, len(seq)))
+		}
+	}
+	if seg.optional.Has("SH") {
+		checksum, err := seg.optional.GetByteArray("SH")
+		sum := sha256.Sum256(seq)
+		switch {
+		case err != nil:
+			reasons = append(reasons, fmt.Sprintf("SH tag is not a byte array: %v", err))
+		case !bytes.Equal(checksum, sum[:]):
+			reasons = append(reasons, "SH tag does not match the SHA-256 checksum of the sequence")
+		}
+	}
+	return reasons
+}
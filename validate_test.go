@@ -0,0 +1,89 @@
+package gfa
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+var validateInput = `H	VN:Z:1
+S	1	ACGT	LN:i:4	SH:H:1DFF3E84FE7877E0673B69BBDDCF40124E396E3F9943DD890C91B6A09ADB9AF0
+S	2	TT	LN:i:3
+S	3	GGA	SH:H:0000000000000000000000000000000000000000000000000000000000000000
+S	4	*	LN:i:2
+L	1	+	2	+	1M
+L	2	-	1	-	1M
+L	2	+	3	+	5M
+L	3	+	9	+	0M
+L	3	+	4	+	3M
+C	1	+	3	+	5	1M
+P	p1	1+,2+,3+	1M
+P	p2	1+,3+	*
+P	p3	1+,2+	1M,0M
+W	s1	0	chr1	*	*	>1>2>9
+`
+
+// test that every problem in a GFA instance is reported, with its severity
+func TestValidateReport(t *testing.T) {
+	myGFA := readTestGFA(t, validateInput)
+	expected := []struct {
+		severity   Severity
+		recordType string
+		reason     string
+	}{
+		{SeverityError, "S", "LN tag (3) does not match the sequence length (2)"},
+		{SeverityError, "S", "SH tag does not match the SHA-256 checksum of the sequence"},
+		{SeverityWarning, "L", "duplicate link between 2- and 1-"},
+		{SeverityError, "L", "overlap 5M is longer than segment 2 (length 2)"},
+		{SeverityError, "L", "overlap 5M is longer than segment 3 (length 3)"},
+		{SeverityError, "L", "references an unknown segment: 9"},
+		{SeverityError, "L", "overlap 3M is longer than segment 4 (length 2)"},
+		{SeverityError, "C", "position 5 is beyond the end of the container (length 4)"},
+		{SeverityError, "P", "path has 3 steps but 1 overlaps"},
+		{SeverityWarning, "P", "no link joins 1+ to 3+"},
+		{SeverityWarning, "P", "path has an overlap for each step, rather than between each pair of steps"},
+		{SeverityError, "W", "references an unknown segment: 9"},
+	}
+	findings := myGFA.ValidateReport()
+	if len(findings) != len(expected) {
+		t.Fatalf("expected %d findings, got %d: %v", len(expected), len(findings), findings)
+	}
+	for i, finding := range findings {
+		if finding.Severity != expected[i].severity || finding.RecordType != expected[i].recordType || finding.Reason != expected[i].reason {
+			t.Fatalf("unexpected finding: %v", finding)
+		}
+	}
+	if !strings.HasPrefix(findings[0].String(), "error: S record") || findings[0].Record == "" {
+		t.Fatalf("finding does not print the record: %v", findings[0])
+	}
+	// Validate only checks the references to segments of containments, jumps and walks
+	if err := myGFA.Validate(); err == nil {
+		t.Fatal("Validate should fail for a walk with an unknown segment")
+	}
+}
+
+// test that the example file has no errors, and that an empty GFA instance is reported
+func TestValidateReportExample(t *testing.T) {
+	fh, err := os.Open(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fh.Close()
+	reader, err := NewReader(fh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	myGFA, err := reader.ReadAll(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, finding := range myGFA.ValidateReport() {
+		if finding.Severity == SeverityError {
+			t.Fatalf("unexpected error in the example file: %v", finding)
+		}
+	}
+	findings := NewGFA().ValidateReport()
+	if len(findings) != 2 || findings[0].String() != "error: GFA instance has no version" {
+		t.Fatalf("unexpected findings for an empty GFA instance: %v", findings)
+	}
+}
@@ -0,0 +1,293 @@
+package gfa
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// GetWalks returns a slice of all the walks held in the GFA instance
+func (gfa *GFA) GetWalks() ([]*Walk, error) {
+	return gfa.walks, nil
+}
+
+// GetJumps returns a slice of all the jumps held in the GFA instance
+func (gfa *GFA) GetJumps() ([]*Jump, error) {
+	return gfa.jumps, nil
+}
+
+// PrintWalkSequence will return the sequence encoded by the walk for a specified sample, haplotype and sequence ID
+func (gfa *GFA) PrintWalkSequence(sampleID []byte, hapIndex int, seqID []byte) ([]byte, error) {
+	if err := gfa.Validate(); err != nil {
+		return nil, err
+	}
+	for _, walk := range gfa.walks {
+		if bytes.Equal(walk.SampleID, sampleID) && walk.hapIndex == hapIndex && bytes.Equal(walk.SeqID, seqID) {
+			// walks have no overlaps of their own, so the overlaps of the links between the segments are used
+			return gfa.spell(walk.names, walk.steps, nil, 0, -1)
+		}
+	}
+	return nil, fmt.Errorf("specified walk not found in GFA")
+}
+
+// A Walk records a haplotype traversal of the graph (added in GFA 1.1)
+type Walk struct {
+	recordType string
+	SampleID   []byte
+	hapIndex   int
+	SeqID      []byte
+	seqStart   int // -1 if not given (*)
+	seqEnd     int // -1 if not given (*)
+	steps      []step
+	names      *nameTable
+	optional   *OptionalFields
+}
+
+// NewWalk is a walk constructor, where the walk is a string of oriented segments (e.g. >s1<s2>s3)
+func NewWalk(sampleID, hapIndex, seqID, seqStart, seqEnd, steps []byte) (*Walk, error) {
+	return newWalk(newNameTable(), sampleID, hapIndex, seqID, seqStart, seqEnd, steps)
+}
+
+// newWalk creates a walk with its segment names interned in the given name table
+func newWalk(names *nameTable, sampleID, hapIndex, seqID, seqStart, seqEnd, steps []byte) (*Walk, error) {
+	if bytes.ContainsAny(sampleID, " \t") {
+		return nil, fieldErrorf(1, "Walk sample ID can't contain whitespace")
+	}
+	if bytes.ContainsAny(seqID, " \t") {
+		return nil, fieldErrorf(3, "Walk sequence ID can't contain whitespace")
+	}
+	walk := &Walk{recordType: "W", SampleID: append([]byte(nil), sampleID...), SeqID: append([]byte(nil), seqID...)}
+	var err error
+	if walk.hapIndex, err = strconv.Atoi(string(hapIndex)); err != nil || walk.hapIndex < 0 {
+		return nil, fieldErrorf(2, "Walk haplotype index must be a non-negative integer: %v", string(hapIndex))
+	}
+	if walk.seqStart, err = parseOptionalInt(seqStart); err != nil {
+		return nil, fieldErrorf(4, "Walk sequence start must be a non-negative integer or *: %v", string(seqStart))
+	}
+	if walk.seqEnd, err = parseOptionalInt(seqEnd); err != nil {
+		return nil, fieldErrorf(5, "Walk sequence end must be a non-negative integer or *: %v", string(seqEnd))
+	}
+	walk.names = names
+	if walk.steps, err = parseWalkSteps(walk.names, steps); err != nil {
+		return nil, newFieldError(6, err)
+	}
+	return walk, nil
+}
+
+// parseOptionalInt converts a non-negative integer field that can also be * (returned as -1)
+func parseOptionalInt(field []byte) (int, error) {
+	if string(field) == "*" {
+		return -1, nil
+	}
+	i, err := strconv.Atoi(string(field))
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("not a non-negative integer: %v", string(field))
+	}
+	return i, nil
+}
+
+// formatOptionalInt prints a non-negative integer field, using * for -1
+func formatOptionalInt(i int) string {
+	if i < 0 {
+		return "*"
+	}
+	return strconv.Itoa(i)
+}
+
+// parseWalkSteps splits a walk (e.g. >s1<s2>s3) into its oriented segments, interning the segment names into a name table
+func parseWalkSteps(names *nameTable, steps []byte) ([]step, error) {
+	if len(steps) == 0 || (steps[0] != '>' && steps[0] != '<') {
+		return nil, fmt.Errorf("Walk must be a series of segment names, each preceded by > or <: %v", string(steps))
+	}
+	walkSteps := []step{}
+	for len(steps) != 0 {
+		orient := "+"
+		if steps[0] == '<' {
+			orient = "-"
+		}
+		end := bytes.IndexAny(steps[1:], "><") + 1
+		if end == 0 {
+			end = len(steps)
+		}
+		if end == 1 {
+			return nil, fmt.Errorf("Walk contains a step with no segment name")
+		}
+		walkSteps = append(walkSteps, names.step(steps[1:end], orient))
+		steps = steps[end:]
+	}
+	return walkSteps, nil
+}
+
+// GetSampleID returns the sample identifier of a walk
+func (walk *Walk) GetSampleID() []byte {
+	return walk.SampleID
+}
+
+// GetSeqID returns the sequence identifier of a walk
+func (walk *Walk) GetSeqID() []byte {
+	return walk.SeqID
+}
+
+// GetSegments returns the names of the segments in a walk, without their orientations
+func (walk *Walk) GetSegments() [][]byte {
+	segs := make([][]byte, len(walk.steps))
+	for i, s := range walk.steps {
+		segs[i] = []byte(walk.names.name(s.id()))
+	}
+	return segs
+}
+
+// GetHapIndex returns the haplotype index of a walk
+func (walk *Walk) GetHapIndex() int {
+	return walk.hapIndex
+}
+
+// GetSeqRange returns the start and end of the walk on its sequence, -1 is returned for any missing value
+func (walk *Walk) GetSeqRange() (int, int) {
+	return walk.seqStart, walk.seqEnd
+}
+
+// GetOrientations returns the orientation (+/-) of each segment in a walk
+func (walk *Walk) GetOrientations() []string {
+	orients := make([]string, len(walk.steps))
+	for i, s := range walk.steps {
+		orients[i] = s.orient()
+	}
+	return orients
+}
+
+// AddOptionalFields adds a set of optional fields to a walk
+func (walk *Walk) AddOptionalFields(oFs *OptionalFields) {
+	walk.optional = oFs
+}
+
+// GetOptionalFields returns the optional fields of a walk (an empty set is added if there are none, so tags can be set on it)
+func (walk *Walk) GetOptionalFields() *OptionalFields {
+	if walk.optional == nil {
+		walk.optional = new(OptionalFields)
+	}
+	return walk.optional
+}
+
+// GetRecordType returns the record type of a walk (W)
+func (walk *Walk) GetRecordType() string {
+	return walk.recordType
+}
+
+// PrintGFAline prints a GFA formatted walk line
+func (walk *Walk) PrintGFAline() string {
+	var steps bytes.Buffer
+	for _, s := range walk.steps {
+		if s.reverse() {
+			steps.WriteByte('<')
+		} else {
+			steps.WriteByte('>')
+		}
+		steps.WriteString(walk.names.name(s.id()))
+	}
+	line := fmt.Sprintf("%v\t%v\t%v\t%v\t%v\t%v\t%v", walk.recordType, string(walk.SampleID), walk.hapIndex, string(walk.SeqID), formatOptionalInt(walk.seqStart), formatOptionalInt(walk.seqEnd), steps.String())
+	return appendOptionalFields(line, walk.optional)
+}
+
+// Add appends a walk to a specified GFA instance
+func (walk *Walk) Add(gfa *GFA) error {
+	for i, s := range walk.steps {
+		walk.steps[i] = gfa.remap(walk.names, s)
+	}
+	walk.names = gfa.names
+	gfa.walks = append(gfa.walks, walk)
+	gfa.track(walk)
+	return nil
+}
+
+// A Jump connects oriented segments that are separated by a (possibly unknown) distance (added in GFA 1.2)
+type Jump struct {
+	recordType string
+	from       step
+	to         step
+	names      *nameTable
+	distance   string // either an integer or * if not known
+	optional   *OptionalFields
+}
+
+// NewJump is a jump constructor
+func NewJump(from, fOrient, to, tOrient, distance []byte) (*Jump, error) {
+	return newJump(newNameTable(), from, fOrient, to, tOrient, distance)
+}
+
+// newJump creates a jump with its segment names interned in the given name table
+func newJump(names *nameTable, from, fOrient, to, tOrient, distance []byte) (*Jump, error) {
+	if bytes.ContainsAny(from, "+-*= ") {
+		return nil, fieldErrorf(1, "Segment name can't contain +/-/*/= or whitespace")
+	}
+	if bytes.ContainsAny(to, "+-*= ") {
+		return nil, fieldErrorf(3, "Segment name can't contain +/-/*/= or whitespace")
+	}
+	jump := &Jump{recordType: "J", names: names}
+	fori, tori := string(fOrient), string(tOrient)
+	if (fori == "+") || (fori == "-") {
+		jump.from = jump.names.step(from, fori)
+	} else {
+		return nil, fieldErrorf(2, "From orientation field must be either + or -")
+	}
+	if (tori == "+") || (tori == "-") {
+		jump.to = jump.names.step(to, tori)
+	} else {
+		return nil, fieldErrorf(4, "To orientation field must be either + or -")
+	}
+	if string(distance) != "*" {
+		if _, err := strconv.Atoi(string(distance)); err != nil {
+			return nil, fieldErrorf(5, "Jump distance must be an integer or *: %v", string(distance))
+		}
+	}
+	jump.distance = string(distance)
+	return jump, nil
+}
+
+// GetDistance returns the distance of a jump, ok is false if the distance is not known (*)
+func (jump *Jump) GetDistance() (int, bool) {
+	distance, err := strconv.Atoi(jump.distance)
+	return distance, err == nil
+}
+
+// AddOptionalFields adds a set of optional fields to a jump
+func (jump *Jump) AddOptionalFields(oFs *OptionalFields) {
+	jump.optional = oFs
+}
+
+// GetOptionalFields returns the optional fields of a jump (an empty set is added if there are none, so tags can be set on it)
+func (jump *Jump) GetOptionalFields() *OptionalFields {
+	if jump.optional == nil {
+		jump.optional = new(OptionalFields)
+	}
+	return jump.optional
+}
+
+// GetRecordType returns the record type of a jump (J)
+func (jump *Jump) GetRecordType() string {
+	return jump.recordType
+}
+
+// GetFrom returns the name and orientation (+/-) of the segment a jump starts from
+func (jump *Jump) GetFrom() ([]byte, string) {
+	return []byte(jump.names.name(jump.from.id())), jump.from.orient()
+}
+
+// GetTo returns the name and orientation (+/-) of the segment a jump goes to
+func (jump *Jump) GetTo() ([]byte, string) {
+	return []byte(jump.names.name(jump.to.id())), jump.to.orient()
+}
+
+// PrintGFAline prints a GFA formatted jump line
+func (jump *Jump) PrintGFAline() string {
+	line := fmt.Sprintf("%v\t%v\t%v\t%v\t%v\t%v", jump.recordType, jump.names.name(jump.from.id()), jump.from.orient(), jump.names.name(jump.to.id()), jump.to.orient(), jump.distance)
+	return appendOptionalFields(line, jump.optional)
+}
+
+// Add appends a jump to a specified GFA instance
+func (jump *Jump) Add(gfa *GFA) error {
+	jump.from, jump.to, jump.names = gfa.remap(jump.names, jump.from), gfa.remap(jump.names, jump.to), gfa.names
+	gfa.jumps = append(gfa.jumps, jump)
+	gfa.track(jump)
+	return nil
+}
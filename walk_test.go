@@ -0,0 +1,88 @@
+package gfa
+
+import (
+	"strings"
+	"testing"
+)
+
+var testWalks = strings.Join([]string{
+	"H\tVN:Z:1.1",
+	"S\ts1\tACCG",
+	"S\ts2\tTTA",
+	"S\ts3\tGCA",
+	"L\ts1\t+\ts2\t+\t0M",
+	"L\ts1\t+\ts3\t-\t0M",
+	"J\ts2\t+\ts3\t+\t*",
+	"W\tsample1\t1\tchr1\t0\t10\t>s1>s2>s3",
+	"W\tsample2\t0\tchr1\t*\t*\t>s1<s3",
+}, "\n") + "\n"
+
+// create some walks and jumps
+func TestNewWalk(t *testing.T) {
+	walk, err := NewWalk([]byte("sample1"), []byte("1"), []byte("chr1"), []byte("*"), []byte("100"), []byte(">s1<s22>s3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(walk.PrintGFAline())
+	if len(walk.GetSegments()) != 3 || string(walk.GetSegments()[1]) != "s22" || walk.GetOrientations()[1] != "-" {
+		t.Fatal("walk steps not parsed correctly")
+	}
+	if start, end := walk.GetSeqRange(); start != -1 || end != 100 {
+		t.Fatal("walk sequence range not parsed correctly")
+	}
+	if walk.PrintGFAline() != "W\tsample1\t1\tchr1\t*\t100\t>s1<s22>s3" {
+		t.Fatal("walk not formatted correctly")
+	}
+	t.Log("testing some bad walks:")
+	for _, steps := range []string{"s1>s2", ">s1<>s2", ""} {
+		if _, err := NewWalk([]byte("sample1"), []byte("1"), []byte("chr1"), []byte("*"), []byte("*"), []byte(steps)); err != nil {
+			t.Log(err)
+		} else {
+			t.Fatalf("bad walk was accepted: %v", steps)
+		}
+	}
+	jump, err := NewJump([]byte("s1"), []byte("+"), []byte("s2"), []byte("-"), []byte("*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := jump.GetDistance(); ok {
+		t.Fatal("unknown jump distance was reported")
+	}
+	if jump.PrintGFAline() != "J\ts1\t+\ts2\t-\t*" {
+		t.Fatal("jump not formatted correctly")
+	}
+	if _, err := NewJump([]byte("s1"), []byte("+"), []byte("s2"), []byte("-"), []byte("far")); err != nil {
+		t.Log(err)
+	} else {
+		t.Fatal("bad jump distance was accepted")
+	}
+}
+
+// read walks and jumps and spell out the walk sequences
+func TestPrintWalkSequence(t *testing.T) {
+	myGFA := readTestGFA(t, testWalks)
+	walks, _ := myGFA.GetWalks()
+	jumps, _ := myGFA.GetJumps()
+	if len(walks) != 2 || len(jumps) != 1 {
+		t.Fatal("did not read the walks and jumps")
+	}
+	seq, err := myGFA.PrintWalkSequence([]byte("sample1"), 1, []byte("chr1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(seq) != "ACCGTTAGCA" {
+		t.Fatalf("walk sequence incorrect: %v", string(seq))
+	}
+	seq, err = myGFA.PrintWalkSequence([]byte("sample2"), 0, []byte("chr1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(seq) != "ACCGTGC" {
+		t.Fatalf("reverse walk sequence incorrect: %v", string(seq))
+	}
+	if _, err := myGFA.PrintWalkSequence([]byte("sample3"), 0, []byte("chr1")); err != nil {
+		t.Log(err)
+	} else {
+		t.Fatal("missing walk did not give an error")
+	}
+}